@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"go/parser"
@@ -8,6 +9,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -33,6 +35,10 @@ func init() {
 	rootCmd.Flags().StringP("output", "o", "", "output file")
 	rootCmd.Flags().String("package", "", "package name")
 	rootCmd.Flags().String("lang", "", "language to generate in")
+	rootCmd.Flags().Bool("check", false, "check the generated code is up to date instead of writing it")
+	rootCmd.Flags().String("split", "", "split the generated code into files per \"kind\" or \"type\", prefixed by the output file name (go only)")
+	rootCmd.Flags().String("go-version", "", "oldest Go version the generated code must compile with, e.g. 1.17 (go only)")
+	rootCmd.Flags().Bool("strict-enums", false, "return an error when decoding unknown enum values (go only)")
 }
 
 func ClientGen(cmd *cobra.Command, args []string) error {
@@ -59,38 +65,233 @@ func ClientGen(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	generated, err := generate(ctx, introspectionSchema, generator.Config{
-		Package: pkg,
-		Lang:    generator.SDKLang(lang),
-	})
+	split, err := cmd.Flags().GetString("split")
 	if err != nil {
 		return err
 	}
 
+	goVersion, err := cmd.Flags().GetString("go-version")
+	if err != nil {
+		return err
+	}
+
+	strictEnums, err := cmd.Flags().GetBool("strict-enums")
+	if err != nil {
+		return err
+	}
+
+	cfg := generator.Config{
+		Package:     pkg,
+		Lang:        generator.SDKLang(lang),
+		SplitFiles:  generator.SplitMode(split),
+		GoVersion:   goVersion,
+		StrictEnums: strictEnums,
+	}
+
 	output, err := cmd.Flags().GetString("output")
 	if err != nil {
 		return err
 	}
 
-	if output == "" || output == "-" {
-		fmt.Fprint(os.Stdout, string(generated))
+	check, err := cmd.Flags().GetBool("check")
+	if err != nil {
+		return err
+	}
+
+	var files map[string][]byte
+	if cfg.SplitFiles != generator.SplitNone {
+		files, err = generateFiles(ctx, introspectionSchema, cfg, output)
+		if err != nil {
+			return err
+		}
 	} else {
-		if err := os.MkdirAll(filepath.Dir(output), 0o700); err != nil {
+		generated, err := generate(ctx, introspectionSchema, cfg)
+		if err != nil {
+			return err
+		}
+
+		if output == "" || output == "-" {
+			if check {
+				return fmt.Errorf("an output file is required to check the generated code")
+			}
+			fmt.Fprint(os.Stdout, string(generated))
+			return nil
+		}
+		files = map[string][]byte{filepath.Clean(output): generated}
+	}
+
+	stale, err := staleFiles(output, files)
+	if err != nil {
+		return err
+	}
+
+	if check {
+		return checkFiles(files, stale)
+	}
+	return writeFiles(files, stale)
+}
+
+// generatedHeader starts the files generated by client-gen, so that only
+// those are removed when they're stale.
+const generatedHeader = "// Code generated by dagger. DO NOT EDIT."
+
+// outputStem returns the name of the output file without its extension,
+// which prefixes the split files.
+// Example: `api.gen.go` -> `api`
+func outputStem(output string) string {
+	name := filepath.Base(output)
+	for _, ext := range []string{".gen.go", ".go"} {
+		if strings.HasSuffix(name, ext) {
+			return strings.TrimSuffix(name, ext)
+		}
+	}
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
+
+// staleFiles returns the files generated for the output by a previous run
+// which aren't generated anymore, e.g. the output file after splitting it,
+// or the files of another split mode.
+func staleFiles(output string, files map[string][]byte) ([]string, error) {
+	if output == "" || output == "-" {
+		return nil, nil
+	}
+	candidates, err := filepath.Glob(filepath.Join(filepath.Dir(output), outputStem(output)+"_*.gen.go"))
+	if err != nil {
+		return nil, err
+	}
+	candidates = append(candidates, filepath.Clean(output))
+
+	var stale []string
+	for _, name := range candidates {
+		if _, ok := files[name]; ok {
+			continue
+		}
+		contents, err := os.ReadFile(name)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if bytes.HasPrefix(contents, []byte(generatedHeader)) {
+			stale = append(stale, name)
+		}
+	}
+	sort.Strings(stale)
+	return stale, nil
+}
+
+// writeFiles writes the generated files and removes the stale ones, marking
+// them as generated in the .gitattributes of their directory.
+func writeFiles(files map[string][]byte, stale []string) error {
+	generated := map[string][]string{}
+	for _, name := range sortedNames(files) {
+		dir := filepath.Dir(name)
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return err
+		}
+		if err := os.WriteFile(name, files[name], 0o600); err != nil {
 			return err
 		}
-		if err := os.WriteFile(output, generated, 0o600); err != nil {
+		generated[dir] = append(generated[dir], filepath.Base(name))
+	}
+
+	removed := map[string][]string{}
+	for _, name := range stale {
+		if err := os.Remove(name); err != nil {
 			return err
 		}
+		removed[filepath.Dir(name)] = append(removed[filepath.Dir(name)], filepath.Base(name))
+	}
 
-		gitAttributes := fmt.Sprintf("/%s linguist-generated=true", filepath.Base(output))
-		if err := os.WriteFile(path.Join(filepath.Dir(output), ".gitattributes"), []byte(gitAttributes), 0o600); err != nil {
+	for dir, names := range generated {
+		if err := updateGitAttributes(dir, names, removed[dir]); err != nil {
 			return err
 		}
 	}
+	return nil
+}
+
+// updateGitAttributes marks the generated files of a directory in its
+// .gitattributes, keeping the other attributes but those of the removed
+// files.
+func updateGitAttributes(dir string, generated, removed []string) error {
+	filename := path.Join(dir, ".gitattributes")
+	marker := func(name string) string {
+		return fmt.Sprintf("/%s linguist-generated=true", name)
+	}
+
+	existing, err := os.ReadFile(filename)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	current := strings.TrimSuffix(string(existing), "\n")
+	var lines []string
+	if current != "" {
+		lines = strings.Split(current, "\n")
+	}
+
+	drop := map[string]bool{}
+	for _, name := range removed {
+		drop[marker(name)] = true
+	}
+	seen := map[string]bool{}
+	kept := make([]string, 0, len(lines)+len(generated))
+	for _, line := range lines {
+		if drop[line] {
+			continue
+		}
+		kept = append(kept, line)
+		seen[line] = true
+	}
+	for _, name := range generated {
+		if !seen[marker(name)] {
+			kept = append(kept, marker(name))
+		}
+	}
+
+	updated := strings.Join(kept, "\n")
+	if updated == current {
+		return nil
+	}
+	return os.WriteFile(filename, []byte(updated), 0o600)
+}
 
+// checkFiles compares the generated files with the existing ones without
+// writing them, printing the differences and the stale files.
+func checkFiles(files map[string][]byte, stale []string) error {
+	outdated := 0
+	for _, name := range sortedNames(files) {
+		diff, err := generator.DiffFile(name, files[name])
+		if err != nil {
+			return err
+		}
+		if diff != "" {
+			outdated++
+			fmt.Fprint(os.Stderr, diff)
+		}
+	}
+	for _, name := range stale {
+		fmt.Fprintf(os.Stderr, "%s is stale: it's not generated anymore\n", name)
+	}
+	switch {
+	case len(stale) > 0:
+		return fmt.Errorf("%d of %d generated files are out of date and %d stale files remain, regenerate the client", outdated, len(files), len(stale))
+	case outdated > 0:
+		return fmt.Errorf("%d of %d generated files are out of date, regenerate the client", outdated, len(files))
+	}
 	return nil
 }
 
+func sortedNames(files map[string][]byte) []string {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 func getLang(cmd *cobra.Command) (string, error) {
 	lang, err := cmd.Flags().GetString("lang")
 	if err != nil {
@@ -144,11 +345,12 @@ func generate(ctx context.Context, introspectionSchema *introspection.Schema, cf
 	generator.SetSchemaParents(introspectionSchema)
 
 	var gen generator.Generator
+	goGen := &gogenerator.GoGenerator{
+		Config: cfg,
+	}
 	switch cfg.Lang {
 	case generator.SDKLangGo:
-		gen = &gogenerator.GoGenerator{
-			Config: cfg,
-		}
+		gen = goGen
 	case generator.SDKLangNodeJS:
 		gen = &nodegenerator.NodeGenerator{}
 
@@ -160,7 +362,45 @@ func generate(ctx context.Context, introspectionSchema *introspection.Schema, cf
 		return []byte{}, fmt.Errorf("use target SDK language: %s: %w", sdks, generator.ErrUnknownSDKLang)
 	}
 
-	return gen.Generate(ctx, introspectionSchema)
+	generated, err := gen.Generate(ctx, introspectionSchema)
+	printWarnings(goGen.Warnings)
+	return generated, err
+}
+
+// generateFiles generates the Go code split into several files, keyed by
+// their path in the directory of the output file.
+func generateFiles(ctx context.Context, introspectionSchema *introspection.Schema, cfg generator.Config, output string) (map[string][]byte, error) {
+	switch {
+	case cfg.Lang != generator.SDKLangGo:
+		return nil, fmt.Errorf("splitting files is only supported for %s", generator.SDKLangGo)
+	case cfg.SplitFiles != generator.SplitByKind && cfg.SplitFiles != generator.SplitByType:
+		return nil, fmt.Errorf("unknown split mode %q, use %q or %q", cfg.SplitFiles, generator.SplitByKind, generator.SplitByType)
+	case output == "" || output == "-":
+		return nil, fmt.Errorf("an output file is required to split files")
+	}
+
+	generator.SetSchemaParents(introspectionSchema)
+	gen := &gogenerator.GoGenerator{Config: cfg}
+	generated, err := gen.GenerateFiles(ctx, introspectionSchema)
+	printWarnings(gen.Warnings)
+	if err != nil {
+		return nil, err
+	}
+
+	// The files are named after the output file, so that they're told
+	// apart from the files generated for other outputs.
+	files := make(map[string][]byte, len(generated))
+	for name, src := range generated {
+		files[filepath.Join(filepath.Dir(output), outputStem(output)+"_"+name)] = src
+	}
+	return files, nil
+}
+
+// printWarnings reports the issues found while generating the code.
+func printWarnings(warnings []string) {
+	for _, w := range warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", w)
+	}
 }
 
 func main() {
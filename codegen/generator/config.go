@@ -0,0 +1,11 @@
+package generator
+
+var _config Config
+
+func SetConfig(config Config) {
+	_config = config
+}
+
+func GetConfig() Config {
+	return _config
+}
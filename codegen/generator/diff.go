@@ -0,0 +1,30 @@
+package generator
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// DiffFile returns a unified diff from the content of a file to generated
+// code, or an empty string if they're identical. A missing file is
+// diffed as empty.
+func DiffFile(path string, generated []byte) (string, error) {
+	current, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return "", err
+	}
+	if string(current) == string(generated) {
+		return "", nil
+	}
+
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(current)),
+		B:        difflib.SplitLines(string(generated)),
+		FromFile: path,
+		ToFile:   path + " (generated)",
+		Context:  3,
+	})
+}
@@ -0,0 +1,27 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api.gen.go")
+
+	diff, err := DiffFile(path, []byte("package dagger\n"))
+	require.NoError(t, err)
+	require.Contains(t, diff, "+package dagger")
+
+	require.NoError(t, os.WriteFile(path, []byte("package dagger\n\ntype ID string\n"), 0o600))
+
+	diff, err = DiffFile(path, []byte("package dagger\n\ntype ID string\n"))
+	require.NoError(t, err)
+	require.Empty(t, diff)
+
+	diff, err = DiffFile(path, []byte("package dagger\n\ntype ID int\n"))
+	require.NoError(t, err)
+	require.Contains(t, diff, "-type ID string\n+type ID int\n")
+}
@@ -0,0 +1,247 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"path"
+
+	"github.com/dagger/dagger/codegen/introspection"
+)
+
+// FilterTypes returns a copy of the schema keeping only the types matching
+// the include glob patterns (all of them when empty), along with the types
+// they reference so that the generated code compiles.
+//
+// The root operation types (e.g. `Query`) are always kept to reach the
+// other types: when they aren't included, only their fields returning kept
+// types are.
+//
+// Types matching the exclude patterns are never kept, even if referenced:
+// the fields returning them are removed, as well as the optional arguments
+// and input fields using them. An error is returned if a kept field
+// requires them as argument or a kept input object as field, since it
+// couldn't be used anymore. Excluding an object excludes its ID too, since
+// the object can be passed instead.
+func FilterTypes(schema *introspection.Schema, include, exclude []string) (*introspection.Schema, error) {
+	for _, pattern := range append(append([]string{}, include...), exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid type pattern %q: %w", pattern, err)
+		}
+	}
+	roots := rootTypes(schema)
+	excluded := func(name string) bool {
+		if roots[name] {
+			return false
+		}
+		if alias, ok := CustomScalar[name]; ok && matchAny(exclude, alias) {
+			return true
+		}
+		return matchAny(exclude, name)
+	}
+
+	kept := map[string]*introspection.Type{}
+	var (
+		queue []*introspection.Type
+		errs  []error
+	)
+	keep := func(t *introspection.Type) {
+		if _, ok := kept[t.Name]; ok || excluded(t.Name) {
+			return
+		}
+		filtered, err := filterType(t, excluded)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		kept[t.Name] = filtered
+		queue = append(queue, filtered)
+	}
+	keepReferences := func() {
+		for len(queue) > 0 {
+			t := queue[0]
+			queue = queue[1:]
+			for _, name := range referencedTypes(t) {
+				if ref := schema.Types.Get(name); ref != nil {
+					keep(ref)
+				}
+			}
+		}
+	}
+
+	var partialRoots []*introspection.Type
+	for _, t := range schema.Types {
+		switch {
+		case len(include) == 0 || matchAny(include, t.Name):
+			keep(t)
+		case roots[t.Name]:
+			partialRoots = append(partialRoots, t)
+		}
+	}
+	keepReferences()
+
+	// The fields of the partial roots pull in the types of their arguments,
+	// which may reference types returned by other fields.
+	for n := -1; n != len(kept); {
+		n = len(kept)
+		for _, root := range partialRoots {
+			for _, f := range rootFields(root, kept, excluded) {
+				for _, arg := range f.Args {
+					if ref := schema.Types.Get(typeName(arg.TypeRef)); ref != nil {
+						keep(ref)
+					}
+				}
+			}
+		}
+		keepReferences()
+	}
+	for _, root := range partialRoots {
+		t := *root
+		t.Fields = rootFields(root, kept, excluded)
+		filtered, err := filterType(&t, excluded)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		kept[t.Name] = filtered
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	filtered := *schema
+	filtered.Types = nil
+	// Keep the order of the schema.
+	for _, t := range schema.Types {
+		if k, ok := kept[t.Name]; ok {
+			filtered.Types = append(filtered.Types, k)
+		}
+	}
+	SetSchemaParents(&filtered)
+	return &filtered, nil
+}
+
+// rootTypes returns the names of the root operation types of a schema,
+// `Query` if they aren't set.
+func rootTypes(schema *introspection.Schema) map[string]bool {
+	roots := map[string]bool{}
+	for _, name := range []string{schema.QueryType.Name, schema.MutationType.Name, schema.SubscriptionType.Name} {
+		if name != "" {
+			roots[name] = true
+		}
+	}
+	if len(roots) == 0 {
+		roots[QueryStructName] = true
+	}
+	return roots
+}
+
+// rootFields returns the fields of a root type which aren't included, and
+// are only kept if they return a kept type and don't require an excluded
+// one.
+func rootFields(root *introspection.Type, kept map[string]*introspection.Type, excluded func(string) bool) []*introspection.Field {
+	var fields []*introspection.Field
+	for _, f := range root.Fields {
+		if _, ok := kept[typeName(f.TypeRef)]; !ok {
+			continue
+		}
+		usable := true
+		for _, arg := range f.Args {
+			usable = usable && (arg.TypeRef.IsOptional() || !excluded(typeName(arg.TypeRef)))
+		}
+		if usable {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// filterType copies a type without the fields, optional arguments, optional
+// input fields and related types referencing excluded types. An error is
+// returned for the required arguments and input fields of excluded types.
+func filterType(t *introspection.Type, excluded func(string) bool) (*introspection.Type, error) {
+	filtered := *t
+	var errs []error
+
+	filtered.Fields = nil
+	for _, f := range t.Fields {
+		if excluded(typeName(f.TypeRef)) {
+			continue
+		}
+		field := *f
+		field.Args = nil
+		for _, arg := range f.Args {
+			if name := typeName(arg.TypeRef); excluded(name) {
+				if !arg.TypeRef.IsOptional() {
+					errs = append(errs, fmt.Errorf("%s.%s requires the excluded type %s as argument %s", t.Name, f.Name, name, arg.Name))
+				}
+				continue
+			}
+			field.Args = append(field.Args, arg)
+		}
+		filtered.Fields = append(filtered.Fields, &field)
+	}
+
+	filtered.InputFields = nil
+	for _, f := range t.InputFields {
+		if name := typeName(f.TypeRef); excluded(name) {
+			if !f.TypeRef.IsOptional() {
+				errs = append(errs, fmt.Errorf("%s.%s requires the excluded type %s", t.Name, f.Name, name))
+			}
+			continue
+		}
+		filtered.InputFields = append(filtered.InputFields, f)
+	}
+
+	filtered.Interfaces = filterTypeRefs(t.Interfaces, excluded)
+	filtered.PossibleTypes = filterTypeRefs(t.PossibleTypes, excluded)
+	return &filtered, errors.Join(errs...)
+}
+
+func filterTypeRefs(refs []*introspection.TypeRef, excluded func(string) bool) []*introspection.TypeRef {
+	var filtered []*introspection.TypeRef
+	for _, ref := range refs {
+		if !excluded(typeName(ref)) {
+			filtered = append(filtered, ref)
+		}
+	}
+	return filtered
+}
+
+// referencedTypes returns the names of the types a type depends on.
+// Object IDs depend on their object, which can be passed instead.
+func referencedTypes(t *introspection.Type) []string {
+	var names []string
+	if alias, ok := CustomScalar[t.Name]; ok {
+		names = append(names, alias)
+	}
+	for _, f := range t.Fields {
+		names = append(names, typeName(f.TypeRef))
+		for _, arg := range f.Args {
+			names = append(names, typeName(arg.TypeRef))
+		}
+	}
+	for _, f := range t.InputFields {
+		names = append(names, typeName(f.TypeRef))
+	}
+	for _, ref := range append(append([]*introspection.TypeRef{}, t.Interfaces...), t.PossibleTypes...) {
+		names = append(names, typeName(ref))
+	}
+	return names
+}
+
+// typeName returns the name of the type wrapped by lists and non-nulls.
+func typeName(r *introspection.TypeRef) string {
+	for ref := r; ref != nil; ref = ref.OfType {
+		if ref.Name != "" {
+			return ref.Name
+		}
+	}
+	return ""
+}
+
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
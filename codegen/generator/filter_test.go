@@ -0,0 +1,126 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dagger/dagger/codegen/introspection"
+)
+
+func testSchema() *introspection.Schema {
+	ref := func(kind introspection.TypeKind, name string) *introspection.TypeRef {
+		return &introspection.TypeRef{Kind: kind, Name: name}
+	}
+	nonNull := func(r *introspection.TypeRef) *introspection.TypeRef {
+		return &introspection.TypeRef{Kind: introspection.TypeKindNonNull, OfType: r}
+	}
+	scalar := func(name string) *introspection.TypeRef {
+		return ref(introspection.TypeKindScalar, name)
+	}
+	object := func(name string) *introspection.TypeRef {
+		return ref(introspection.TypeKindObject, name)
+	}
+
+	return &introspection.Schema{Types: introspection.Types{
+		{Kind: introspection.TypeKindScalar, Name: "String"},
+		{Kind: introspection.TypeKindScalar, Name: "ContainerID"},
+		{Kind: introspection.TypeKindScalar, Name: "CacheID"},
+		{Kind: introspection.TypeKindObject, Name: "Query", Fields: []*introspection.Field{
+			{Name: "container", TypeRef: nonNull(object("Container")), Args: introspection.InputValues{
+				{Name: "id", TypeRef: scalar("ContainerID")},
+			}},
+			{Name: "cacheVolume", TypeRef: nonNull(object("CacheVolume")), Args: introspection.InputValues{
+				{Name: "key", TypeRef: nonNull(scalar("String"))},
+			}},
+			{Name: "unused", TypeRef: object("Unused")},
+		}},
+		{Kind: introspection.TypeKindObject, Name: "Container", Fields: []*introspection.Field{
+			{Name: "id", TypeRef: nonNull(scalar("ContainerID"))},
+			{Name: "withMountedCache", TypeRef: nonNull(object("Container")), Args: introspection.InputValues{
+				{Name: "path", TypeRef: nonNull(scalar("String"))},
+				{Name: "cache", TypeRef: nonNull(scalar("CacheID"))},
+			}},
+			{Name: "withExec", TypeRef: nonNull(object("Container")), Args: introspection.InputValues{
+				{Name: "args", TypeRef: nonNull(scalar("String"))},
+				{Name: "cache", TypeRef: scalar("CacheID")},
+			}},
+			{Name: "withMount", TypeRef: nonNull(object("Container")), Args: introspection.InputValues{
+				{Name: "mount", TypeRef: ref(introspection.TypeKindInputObject, "Mount")},
+			}},
+		}},
+		{Kind: introspection.TypeKindInputObject, Name: "Mount", InputFields: introspection.InputValues{
+			{Name: "path", TypeRef: nonNull(scalar("String"))},
+			{Name: "cache", TypeRef: nonNull(scalar("CacheID"))},
+		}},
+		{Kind: introspection.TypeKindObject, Name: "CacheVolume", Fields: []*introspection.Field{
+			{Name: "id", TypeRef: nonNull(scalar("CacheID"))},
+		}},
+		{Kind: introspection.TypeKindObject, Name: "Unused"},
+	}}
+}
+
+func typeNames(s *introspection.Schema) []string {
+	var names []string
+	for _, t := range s.Types {
+		names = append(names, t.Name)
+	}
+	return names
+}
+
+func TestFilterTypes(t *testing.T) {
+	schema := testSchema()
+
+	filtered, err := FilterTypes(schema, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, typeNames(schema), typeNames(filtered))
+
+	// Referenced types are pulled in, including the objects of IDs, and the
+	// root is kept with the fields returning them.
+	filtered, err = FilterTypes(schema, []string{"Container"}, nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"String", "ContainerID", "CacheID", "Query", "Container", "Mount", "CacheVolume"}, typeNames(filtered))
+	query := filtered.Types.Get("Query")
+	require.Len(t, query.Fields, 2)
+	require.Equal(t, "container", query.Fields[0].Name)
+	require.Equal(t, "cacheVolume", query.Fields[1].Name)
+	require.Equal(t, query, query.Fields[0].ParentObject)
+
+	// Types required by the kept arguments and input fields can't be
+	// excluded.
+	_, err = FilterTypes(schema, nil, []string{"CacheVolume"})
+	require.ErrorContains(t, err, "Container.withMountedCache requires the excluded type CacheID as argument cache")
+	require.ErrorContains(t, err, "Mount.cache requires the excluded type CacheID")
+
+	// Without them, fields returning an excluded type are removed, so are
+	// the optional arguments and input fields using one.
+	schema = testSchema()
+	container := schema.Types.Get("Container")
+	container.Fields = []*introspection.Field{container.Fields[0], container.Fields[2]}
+	filtered, err = FilterTypes(schema, []string{"Q*"}, []string{"Cache*"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"String", "ContainerID", "Query", "Container", "Unused"}, typeNames(filtered))
+	require.Len(t, filtered.Types.Get("Query").Fields, 2)
+	container = filtered.Types.Get("Container")
+	require.Len(t, container.Fields, 2)
+	require.Equal(t, "withExec", container.Fields[1].Name)
+	require.Len(t, container.Fields[1].Args, 1)
+
+	// Excluding an object excludes its ID.
+	filtered, err = FilterTypes(schema, []string{"Container"}, []string{"CacheVolume"})
+	require.NoError(t, err)
+	require.NotContains(t, typeNames(filtered), "CacheID")
+	require.Len(t, filtered.Types.Get("Query").Fields, 1)
+
+	// The root is kept even if excluded.
+	filtered, err = FilterTypes(schema, []string{"Container"}, []string{"Query"})
+	require.NoError(t, err)
+	require.Contains(t, typeNames(filtered), "Query")
+
+	// The original schema is left untouched.
+	require.Len(t, schema.Types.Get("Container").Fields, 2)
+	require.Len(t, schema.Types.Get("Container").Fields[1].Args, 2)
+
+	_, err = FilterTypes(schema, []string{"[Container"}, nil)
+	require.ErrorContains(t, err, "invalid type pattern")
+}
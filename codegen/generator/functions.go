@@ -26,20 +26,82 @@ var CustomScalar = map[string]string{
 	"ProjectCommandID": "ProjectCommand",
 }
 
+// scalars holds the scalar mappings registered with RegisterScalar.
+var scalars = map[string]string{}
+
+// RegisterScalar maps a GraphQL scalar (e.g. `URI`) to an existing type of
+// the SDK language (e.g. `net/url.URL`) instead of generating a named type
+// for it. Types from other packages must be fully-qualified with their
+// import path so the generator can import them.
+//
+// Registered scalars take precedence over the built-in mappings, including
+// the `ID` and `DateTime` scalars and the CustomScalar IDs.
+// Only used for the SDKLangGo.
+func RegisterScalar(graphqlName, typ string) {
+	scalars[graphqlName] = typ
+}
+
+// UnregisterScalar removes a scalar mapping added with RegisterScalar.
+func UnregisterScalar(graphqlName string) {
+	delete(scalars, graphqlName)
+}
+
+// ResetScalars removes all the scalar mappings added with RegisterScalar.
+func ResetScalars() {
+	scalars = map[string]string{}
+}
+
+// LookupScalar returns the type registered for a GraphQL scalar, if any.
+func LookupScalar(graphqlName string) (string, bool) {
+	typ, ok := scalars[graphqlName]
+	return typ, ok
+}
+
 // FormatTypeFuncs is an interface to format any GraphQL type.
 // Each generator has to implement this interface.
 type FormatTypeFuncs interface {
+	// FormatKindList wraps the representation of the list elements.
 	FormatKindList(representation string) string
+	// FormatNullable wraps the representation of a type that isn't
+	// non-null (`!`), once the type is fully formatted.
+	FormatNullable(representation string, t NullableType) string
 	FormatKindScalarString(representation string) string
 	FormatKindScalarInt(representation string) string
 	FormatKindScalarFloat(representation string) string
 	FormatKindScalarBoolean(representation string) string
 	FormatKindScalarDefault(representation string, refName string, input bool) string
 	FormatKindObject(representation string, refName string) string
+	FormatKindInterface(representation string, refName string) string
+	FormatKindUnion(representation string, refName string) string
 	FormatKindInputObject(representation string, refName string) string
 	FormatKindEnum(representation string, refName string) string
 }
 
+// TypeUsage tells where a formatted type is used, since SDKs can represent
+// nullable types differently depending on it.
+type TypeUsage int
+
+const (
+	// UsageOutput is the type of a value returned by the API.
+	UsageOutput TypeUsage = iota
+	// UsageReturn is the type returned by the SDK function of a field.
+	UsageReturn
+	// UsageArgument is the type of a field argument.
+	UsageArgument
+	// UsageInputField is the type of an input object field.
+	UsageInputField
+)
+
+// NullableType describes a nullable type passed to FormatNullable.
+type NullableType struct {
+	// Ref is the nullable type reference.
+	Ref *introspection.TypeRef
+	// Elem is true for the elements of a list.
+	Elem bool
+	// Usage tells where the type is used.
+	Usage TypeUsage
+}
+
 // CommonFunctions formatting function with global shared template functions.
 type CommonFunctions struct {
 	formatTypeFuncs FormatTypeFuncs
@@ -67,7 +129,7 @@ func (c *CommonFunctions) IsSelfChainable(t introspection.Type) bool {
 // unless it's an ID that will be converted which needs to be formatted
 // as an input (for chaining).
 func (c *CommonFunctions) FormatReturnType(f introspection.Field) string {
-	return c.formatType(f.TypeRef, c.ConvertID(f))
+	return c.formatType(f.TypeRef, c.ConvertID(f), UsageReturn)
 }
 
 func (c *CommonFunctions) ToLowerCase(s string) string {
@@ -147,24 +209,50 @@ func (c *CommonFunctions) ConvertID(f introspection.Field) bool {
 //
 // Example: `String` -> `string`
 func (c *CommonFunctions) FormatInputType(r *introspection.TypeRef) string {
-	return c.formatType(r, true)
+	return c.formatType(r, true, UsageArgument)
+}
+
+// FormatInputFieldType formats a GraphQL type into the SDK language input
+// object field
+//
+// Example: `String` -> `string`
+func (c *CommonFunctions) FormatInputFieldType(r *introspection.TypeRef) string {
+	return c.formatType(r, true, UsageInputField)
 }
 
 // FormatOutputType formats a GraphQL type into the SDK language output
 //
 // Example: `String` -> `string`
 func (c *CommonFunctions) FormatOutputType(r *introspection.TypeRef) string {
-	return c.formatType(r, false)
+	return c.formatType(r, false, UsageOutput)
 }
 
 // formatType loops through the type reference to transform it into its SDK language.
-func (c *CommonFunctions) formatType(r *introspection.TypeRef, input bool) (representation string) {
+func (c *CommonFunctions) formatType(r *introspection.TypeRef, input bool, usage TypeUsage) (representation string) {
+	nullable := true
+	elem := false
 	for ref := r; ref != nil; ref = ref.OfType {
+		if ref.Kind == introspection.TypeKindNonNull {
+			nullable = false
+			continue
+		}
+		if nullable {
+			// Like lists, nullable types are wrapped at the end of the loop.
+			// The defer is registered first so that a nullable list wraps
+			// the list itself.
+			t := NullableType{Ref: ref, Elem: elem, Usage: usage}
+			defer func() {
+				representation = c.formatTypeFuncs.FormatNullable(representation, t)
+			}()
+		}
+		nullable = true
+
 		switch ref.Kind {
 		case introspection.TypeKindList:
 			// Handle this special case with defer to format array at the end of
 			// the loop.
 			// Since an SDK needs to insert it at the end, other at the beginning.
+			elem = true
 			defer func() {
 				representation = c.formatTypeFuncs.FormatKindList(representation)
 			}()
@@ -183,6 +271,10 @@ func (c *CommonFunctions) formatType(r *introspection.TypeRef, input bool) (repr
 			}
 		case introspection.TypeKindObject:
 			return c.formatTypeFuncs.FormatKindObject(representation, ref.Name)
+		case introspection.TypeKindInterface:
+			return c.formatTypeFuncs.FormatKindInterface(representation, ref.Name)
+		case introspection.TypeKindUnion:
+			return c.formatTypeFuncs.FormatKindUnion(representation, ref.Name)
 		case introspection.TypeKindInputObject:
 			return c.formatTypeFuncs.FormatKindInputObject(representation, ref.Name)
 		case introspection.TypeKindEnum:
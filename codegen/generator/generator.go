@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/dagger/dagger/codegen/introspection"
 	"github.com/dagger/dagger/core/schema"
@@ -21,23 +23,168 @@ const (
 	SDKLangPython SDKLang = "python"
 )
 
+// SplitMode tells how the generated code is split into files.
+type SplitMode string
+
+const (
+	// SplitNone generates a single file.
+	SplitNone SplitMode = ""
+	// SplitByKind generates a file per kind of type (enums, inputs, ...).
+	SplitByKind SplitMode = "kind"
+	// SplitByType generates a file per top-level type.
+	SplitByType SplitMode = "type"
+)
+
 type Config struct {
 	Lang SDKLang
 	// Package is the target package that is generated.
 	// Not used for the SDKLangNodeJS.
 	Package string
+	// IDType is the type the built-in GraphQL `ID` scalar is mapped to.
+	// When empty, a dedicated `ID` string type is generated.
+	// Only used for the SDKLangGo.
+	IDType string
+	// DecimalType is the fully-qualified type the decimal scalars (e.g.
+	// `Decimal`) are mapped to, like `github.com/shopspring/decimal.Decimal`.
+	// When empty, they're mapped to `*math/big.Rat`, which is decoded from
+	// JSON strings only.
+	// Only used for the SDKLangGo.
+	DecimalType string
+	// UploadType is the fully-qualified type the `Upload` scalar of files
+	// sent with a multipart request is mapped to, either an interface or a
+	// pointer like `*mime/multipart.FileHeader`. When empty, it's mapped to
+	// `io.Reader`.
+	// Only used for the SDKLangGo.
+	UploadType string
+	// NullableOutputPointers makes fields returning a nullable scalar or
+	// enum return a pointer, so that null can be told apart from the zero value.
+	// Only used for the SDKLangGo.
+	NullableOutputPointers bool
+	// NullableListPointers keeps the nullability of lists and of their
+	// elements: `[Int!]` is generated as `*[]int` and `[Int]!` as `[]*int`.
+	// Only used for the SDKLangGo.
+	NullableListPointers bool
+	// Initialisms lists additional initialisms (e.g. `GPU`) kept upper-cased
+	// in generated names, on top of the common ones (`ID`, `URL`, ...).
+	// Only used for the SDKLangGo.
+	Initialisms []string
+	// NameFormatter overrides how GraphQL names are cased into generated
+	// identifiers. When nil, the default strategy is used.
+	// Only used for the SDKLangGo.
+	NameFormatter NameFormatter
+	// FieldNameTransformer post-processes the formatted names of fields,
+	// arguments and input fields, e.g. to pluralize the ones that are lists.
+	// When nil, names are left as formatted.
+	// Only used for the SDKLangGo.
+	FieldNameTransformer FieldNameTransformer
+	// TypeOverrides maps object and input object names to existing types
+	// used instead of generated ones. Types from other packages must be
+	// fully-qualified with their import path (e.g. `example.com/build.Arg`),
+	// the package being named after its last element without its major
+	// version (e.g. `yaml` for `gopkg.in/yaml.v3.Node`, `bar` for
+	// `example.com/bar/v2.Baz`).
+	// Objects are built from a query selection, so they can only be mapped
+	// to types of the generated package with the same unexported fields:
+	// generating fails otherwise.
+	// Only used for the SDKLangGo.
+	TypeOverrides map[string]string
+	// FieldTypeOverrides maps field paths, `Type.field`, to existing types
+	// used for a single field instead of its formatted type (e.g.
+	// `File.size` to `encoding/json.Number`), fully-qualified like
+	// TypeOverrides. Only fields returning scalars and input object fields
+	// can be overridden.
+	// The types aren't checked: they must decode from the JSON value of the
+	// field, strings requiring a json.Unmarshaler or an
+	// encoding.TextUnmarshaler (e.g. `net/netip.Addr`, but not
+	// `net/url.URL`), otherwise querying the field returns the decoding
+	// error. Input values implementing encoding.TextMarshaler are sent as
+	// strings.
+	// Only used for the SDKLangGo.
+	FieldTypeOverrides map[string]string
+	// StrictEnums makes generated enums return an error when decoding
+	// unknown values instead of keeping them, e.g. values added by a newer
+	// API.
+	// Only used for the SDKLangGo.
+	StrictEnums bool
+	// OmitDeprecatedEnumValues leaves the deprecated values out of the
+	// generated `AllX` slices of enum values. Their constants are still
+	// generated.
+	// Only used for the SDKLangGo.
+	OmitDeprecatedEnumValues bool
+	// UnknownScalarsAsAny maps the scalars without a Go mapping to `any`,
+	// with a warning, instead of generating a named type for each of them.
+	// Only used for the SDKLangGo.
+	UnknownScalarsAsAny bool
+	// PresenceHelpers generates `GetX` and `HasX` methods for the nullable
+	// fields of input objects stored as pointers, and for the fields
+	// returning pointers with NullableOutputPointers.
+	// Only used for the SDKLangGo.
+	PresenceHelpers bool
+	// DeepCopy generates a `DeepCopy` method for the objects and input
+	// objects, cloning their slices, maps and pointers.
+	// Only used for the SDKLangGo.
+	DeepCopy bool
+	// ValidateInputs generates a `Validate` method for the input objects,
+	// reporting their required fields left to the zero value. It's opt-in
+	// since the zero value may be a legit value for some fields.
+	// Only used for the SDKLangGo.
+	ValidateInputs bool
+	// OneOfInputs are the names of the input objects to handle as if they had
+	// the `@oneOf` directive, for the servers not reporting it (see
+	// introspection.Type.IsOneOf).
+	// Only used for the SDKLangGo.
+	OneOfInputs []string
+	// StructTags are the formats of the tags of the generated struct fields,
+	// among `json`, `yaml` and `bson`, all keyed by the GraphQL names. When
+	// empty, only `json` tags are generated.
+	// Only used for the SDKLangGo.
+	StructTags []string
+	// TypeNamePrefix and TypeNameSuffix are added to the names of all the
+	// generated types (e.g. `Github` for `GithubUser`), to generate several
+	// clients in the same package. They're added to the Client the Query
+	// fields are generated for too (e.g. `GithubClient`), which has to be
+	// declared along with the generated code. GraphQL names are left as-is.
+	// Only used for the SDKLangGo.
+	TypeNamePrefix string
+	TypeNameSuffix string
+	// GoVersion is the oldest Go version (e.g. `1.17`) the generated code
+	// must compile with, so that `interface{}` is used instead of `any`
+	// before 1.18. When empty, the generated code targets the latest one.
+	// Only used for the SDKLangGo.
+	GoVersion string
+	// SplitFiles splits the generated code into several files of the same
+	// package.
+	// Only used for the SDKLangGo.
+	SplitFiles SplitMode
+	// IncludeTypes and ExcludeTypes are glob patterns (e.g. `Container*`)
+	// selecting the types to generate, see FilterTypes. The types referenced
+	// by the included ones are generated too.
+	// Only used for the SDKLangGo.
+	IncludeTypes []string
+	ExcludeTypes []string
 }
 
+// NameFormatter formats a GraphQL name (e.g. object, field, arg) into an
+// identifier of the SDK language.
+type NameFormatter func(name string) string
+
+// FieldNameTransformer transforms the formatted name of a field, knowing
+// whether its type is a list (e.g. `Item` -> `Items`).
+type FieldNameTransformer func(name string, list bool) string
+
 type Generator interface {
 	Generate(ctx context.Context, schema *introspection.Schema) ([]byte, error)
 }
 
-// SetSchemaParents sets all the parents for the fields.
+// SetSchemaParents sets all the parents for the fields and input fields.
 func SetSchemaParents(schema *introspection.Schema) {
 	for _, t := range schema.Types {
 		for _, f := range t.Fields {
 			f.ParentObject = t
 		}
+		for i := range t.InputFields {
+			t.InputFields[i].ParentObject = t
+		}
 	}
 }
 
@@ -47,8 +194,17 @@ func Introspect(ctx context.Context) (*introspection.Schema, error) {
 	if err != nil {
 		return nil, err
 	}
-	apiSchema := api.Schema()
-	resp := graphql.Do(graphql.Params{Schema: *apiSchema, RequestString: introspection.Query, Context: ctx})
+	return introspect(ctx, *api.Schema())
+}
+
+// introspect runs the introspection query against a schema, without the
+// `isOneOf` field of the types if the schema doesn't know it, which is the
+// case of the engine.
+func introspect(ctx context.Context, apiSchema graphql.Schema) (*introspection.Schema, error) {
+	resp := graphql.Do(graphql.Params{Schema: apiSchema, RequestString: introspection.Query, Context: ctx})
+	if rejectsOneOf(resp) {
+		resp = graphql.Do(graphql.Params{Schema: apiSchema, RequestString: introspection.QueryWithoutOneOf, Context: ctx})
+	}
 	if resp.Errors != nil {
 		errs := make([]error, len(resp.Errors))
 		for i, err := range resp.Errors {
@@ -66,9 +222,28 @@ func Introspect(ctx context.Context) (*introspection.Schema, error) {
 	if err != nil {
 		return nil, fmt.Errorf("unmarshal data: %w", err)
 	}
+
+	// The engine defines enum values in maps, so their order changes from
+	// one run to the other: sort them to keep the generated code stable.
+	for _, t := range introspectionResp.Schema.Types {
+		sort.SliceStable(t.EnumValues, func(i, j int) bool {
+			return t.EnumValues[i].Name < t.EnumValues[j].Name
+		})
+	}
 	return introspectionResp.Schema, nil
 }
 
+// rejectsOneOf returns true if the introspection query failed because the
+// server doesn't know the `isOneOf` field of the types.
+func rejectsOneOf(resp *graphql.Result) bool {
+	for _, err := range resp.Errors {
+		if strings.Contains(err.Message, `"isOneOf"`) {
+			return true
+		}
+	}
+	return false
+}
+
 // IntrospectAndGenerate generate the Dagger API
 func IntrospectAndGenerate(ctx context.Context, generator Generator) ([]byte, error) {
 	schema, err := Introspect(ctx)
@@ -0,0 +1,45 @@
+package generator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dagger/dagger/codegen/introspection"
+	"github.com/dagger/graphql"
+	"github.com/stretchr/testify/require"
+)
+
+// TestIntrospectWithoutOneOf introspects a server which doesn't know the
+// `isOneOf` field of the types.
+func TestIntrospectWithoutOneOf(t *testing.T) {
+	filter := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "Filter",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"name": &graphql.InputObjectFieldConfig{Type: graphql.String},
+		},
+	})
+	apiSchema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"files": &graphql.Field{
+					Type: graphql.NewList(graphql.String),
+					Args: graphql.FieldConfigArgument{
+						{Name: "filter", Type: filter},
+					},
+				},
+			},
+		}),
+	})
+	require.NoError(t, err)
+
+	resp := graphql.Do(graphql.Params{Schema: apiSchema, RequestString: introspection.Query, Context: context.Background()})
+	require.True(t, rejectsOneOf(resp), resp.Errors)
+
+	schema, err := introspect(context.Background(), apiSchema)
+	require.NoError(t, err)
+	typ := schema.Types.Get("Filter")
+	require.NotNil(t, typ)
+	require.False(t, typ.IsOneOf)
+	require.Len(t, typ.InputFields, 1)
+}
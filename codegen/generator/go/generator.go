@@ -4,8 +4,16 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"go/ast"
 	"go/format"
+	"go/parser"
+	"go/token"
+	"path"
+	"sort"
 	"strings"
+	"text/template"
+
+	"github.com/iancoleman/strcase"
 
 	"github.com/dagger/dagger/codegen/generator"
 	"github.com/dagger/dagger/codegen/generator/go/templates"
@@ -14,64 +22,196 @@ import (
 
 type GoGenerator struct {
 	Config generator.Config
+	// FormatTypeFunc formats the GraphQL types into Go ones. When nil, the
+	// zero templates.FormatTypeFunc is used.
+	FormatTypeFunc *templates.FormatTypeFunc
+	// Warnings are set by Generate and GenerateFiles to the issues which
+	// don't prevent generating the code, e.g. scalars formatted as `any`.
+	Warnings []string
+}
+
+// DefaultFileName is the name of the file generated when the code isn't
+// split.
+const DefaultFileName = "api.gen.go"
+
+// renderedType is the generated code of a GraphQL type, along with the
+// packages it requires.
+type renderedType struct {
+	Type    *introspection.Type
+	Source  string
+	Imports []string
 }
 
 func (g *GoGenerator) Generate(_ context.Context, schema *introspection.Schema) ([]byte, error) {
+	rendered, err := g.render(schema)
+	if err != nil {
+		return nil, err
+	}
+	return g.formatFile(schema, rendered)
+}
+
+// GenerateFiles generates the code split into several files of the same
+// package according to the SplitFiles config, keyed by file name.
+func (g *GoGenerator) GenerateFiles(_ context.Context, schema *introspection.Schema) (map[string][]byte, error) {
+	rendered, err := g.render(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	byFile := map[string][]renderedType{}
+	for _, r := range rendered {
+		name := fileName(r.Type, g.Config.SplitFiles)
+		if _, ok := byFile[name]; !ok {
+			names = append(names, name)
+		}
+		byFile[name] = append(byFile[name], r)
+	}
+
+	files := make(map[string][]byte, len(names))
+	for _, name := range names {
+		src, err := g.formatFile(schema, byFile[name])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		// The header imports the packages used by the query builders,
+		// which only some of the files need.
+		if g.Config.SplitFiles != generator.SplitNone {
+			if src, err = pruneImports(src); err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+		}
+		files[name] = src
+	}
+	return files, nil
+}
+
+// render executes the templates of every type of the schema.
+func (g *GoGenerator) render(schema *introspection.Schema) ([]renderedType, error) {
+	if len(g.Config.IncludeTypes) > 0 || len(g.Config.ExcludeTypes) > 0 {
+		filtered, err := generator.FilterTypes(schema, g.Config.IncludeTypes, g.Config.ExcludeTypes)
+		if err != nil {
+			return nil, err
+		}
+		schema = filtered
+	}
+
+	if v := g.Config.GoVersion; v != "" && !templates.IsValidGoVersion(v) {
+		return nil, fmt.Errorf("invalid Go version %q", v)
+	}
+	for _, format := range g.Config.StructTags {
+		if !templates.IsValidStructTag(format) {
+			return nil, fmt.Errorf("unknown struct tag %q, use one of %s", format, strings.Join(templates.StructTagFormats, ", "))
+		}
+	}
+
 	generator.SetSchema(schema)
+	generator.SetConfig(g.Config)
+	templates.SetFormatTypeFunc(g.FormatTypeFunc)
 
-	headerData := struct {
-		Package string
-		Schema  *introspection.Schema
-	}{
-		Package: g.Config.Package,
-		Schema:  schema,
+	warnings, err := checkScalars(schema)
+	if err != nil {
+		return nil, err
 	}
-	var header bytes.Buffer
-	if err := templates.Header.Execute(&header, headerData); err != nil {
+	g.Warnings = warnings
+	if err := checkTypeOverrides(schema); err != nil {
 		return nil, err
 	}
 
-	render := []string{
-		header.String(),
+	rendered := []renderedType{}
+	// execute renders a type with the given templates, keeping track of
+	// the packages it requires.
+	execute := func(t *introspection.Type, tmpls ...*template.Template) error {
+		templates.ResetImports()
+		var out bytes.Buffer
+		for _, tmpl := range tmpls {
+			if err := tmpl.Execute(&out, t); err != nil {
+				return err
+			}
+		}
+		rendered = append(rendered, renderedType{
+			Type:    t,
+			Source:  out.String(),
+			Imports: templates.Imports(),
+		})
+		return nil
+	}
+
+	// The built-in `ID` scalar is skipped by the visitor: only generate
+	// its dedicated type if it's actually referenced and not remapped.
+	if templates.GeneratesIDType() && usesType(schema, string(introspection.ScalarID)) {
+		if err := execute(schema.Types.Get(string(introspection.ScalarID)), templates.Scalar); err != nil {
+			return nil, err
+		}
 	}
 
-	err := schema.Visit(introspection.VisitHandlers{
+	err = schema.Visit(introspection.VisitHandlers{
 		Scalar: func(t *introspection.Type) error {
-			var out bytes.Buffer
-			if err := templates.Scalar.Execute(&out, t); err != nil {
-				return err
+			// Scalars mapped to an existing Go type don't need to be generated.
+			if _, _, ok := templates.MappedScalar(t.Name); ok {
+				return nil
+			}
+			if templates.IsAnyScalar(t.Name) {
+				return nil
 			}
-			render = append(render, out.String())
-			return nil
+			return execute(t, templates.Scalar)
 		},
 		Object: func(t *introspection.Type) error {
-			var out bytes.Buffer
-			if err := templates.Object.Execute(&out, t); err != nil {
-				return err
+			// Overridden types are provided by the user.
+			if _, _, ok := templates.OverriddenType(t.Name); ok {
+				return nil
 			}
-			render = append(render, out.String())
-			return nil
+			return execute(t, templates.Object)
+		},
+		Interface: func(t *introspection.Type) error {
+			// The interface fields are implemented by a query builder, like objects.
+			return execute(t, templates.Interface, templates.Object)
+		},
+		Union: func(t *introspection.Type) error {
+			return execute(t, templates.Union)
 		},
 		Enum: func(t *introspection.Type) error {
-			var out bytes.Buffer
-			if err := templates.Enum.Execute(&out, t); err != nil {
-				return err
-			}
-			render = append(render, out.String())
-			return nil
+			return execute(t, templates.Enum)
 		},
 		Input: func(t *introspection.Type) error {
-			var out bytes.Buffer
-			if err := templates.Input.Execute(&out, t); err != nil {
-				return err
+			if _, _, ok := templates.OverriddenType(t.Name); ok {
+				return nil
 			}
-			render = append(render, out.String())
-			return nil
+			return execute(t, templates.Input)
 		},
 	})
 	if err != nil {
 		return nil, err
 	}
+	return rendered, nil
+}
+
+// formatFile assembles rendered types into a formatted Go file, with the
+// header importing the packages they require.
+func (g *GoGenerator) formatFile(schema *introspection.Schema, rendered []renderedType) ([]byte, error) {
+	imports := map[string]struct{}{}
+	render := make([]string, 0, len(rendered)+1)
+	for _, r := range rendered {
+		for _, importPath := range r.Imports {
+			imports[importPath] = struct{}{}
+		}
+		render = append(render, r.Source)
+	}
+
+	headerData := struct {
+		Package string
+		Schema  *introspection.Schema
+		Imports []string
+	}{
+		Package: g.Config.Package,
+		Schema:  schema,
+		Imports: sortedKeys(imports),
+	}
+	var header bytes.Buffer
+	if err := templates.Header.Execute(&header, headerData); err != nil {
+		return nil, err
+	}
+	render = append([]string{header.String()}, render...)
 
 	formatted, err := format.Source(
 		[]byte(strings.Join(render, "\n")),
@@ -81,3 +221,177 @@ func (g *GoGenerator) Generate(_ context.Context, schema *introspection.Schema)
 	}
 	return formatted, nil
 }
+
+// fileName returns the name of the file generating a type.
+// Example: `ContainerID` -> `scalars.gen.go` when split by kind,
+// `container_id.gen.go` when split by type
+func fileName(t *introspection.Type, mode generator.SplitMode) string {
+	switch mode {
+	case generator.SplitByKind:
+		switch t.Kind {
+		case introspection.TypeKindScalar:
+			return "scalars.gen.go"
+		case introspection.TypeKindObject:
+			return "objects.gen.go"
+		case introspection.TypeKindInterface:
+			return "interfaces.gen.go"
+		case introspection.TypeKindUnion:
+			return "unions.gen.go"
+		case introspection.TypeKindEnum:
+			return "enums.gen.go"
+		case introspection.TypeKindInputObject:
+			return "inputs.gen.go"
+		}
+	case generator.SplitByType:
+		return strcase.ToSnake(t.Name) + ".gen.go"
+	}
+	return DefaultFileName
+}
+
+// pruneImports removes the imports a Go file doesn't use.
+func pruneImports(src []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	// Packages aren't resolved by the parser, so used ones are unresolved.
+	used := map[string]struct{}{}
+	for _, ident := range f.Unresolved {
+		used[ident.Name] = struct{}{}
+	}
+
+	decls := f.Decls[:0]
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			decls = append(decls, decl)
+			continue
+		}
+		specs := gen.Specs[:0]
+		for _, spec := range gen.Specs {
+			imp := spec.(*ast.ImportSpec)
+			name := path.Base(strings.Trim(imp.Path.Value, `"`))
+			if imp.Name != nil {
+				name = imp.Name.Name
+			}
+			if _, ok := used[name]; ok {
+				specs = append(specs, spec)
+			}
+		}
+		gen.Specs = specs
+		if len(specs) > 0 {
+			decls = append(decls, gen)
+		}
+	}
+	f.Decls = decls
+
+	var out bytes.Buffer
+	if err := format.Node(&out, fset, f); err != nil {
+		return nil, err
+	}
+	return format.Source(out.Bytes())
+}
+
+func sortedKeys(m map[string]struct{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// checkScalars makes sure all the scalars referenced by the schema can be
+// formatted, so that the generated code compiles. Scalars formatted as
+// `any` are returned as warnings.
+func checkScalars(schema *introspection.Schema) ([]string, error) {
+	var names []string
+	seen := map[string]struct{}{}
+	walkTypeRefs(schema, func(ref *introspection.TypeRef) {
+		if ref.Kind != introspection.TypeKindScalar {
+			return
+		}
+		if _, ok := seen[ref.Name]; !ok {
+			seen[ref.Name] = struct{}{}
+			names = append(names, ref.Name)
+		}
+	})
+	sort.Strings(names)
+
+	var warnings []string
+	for _, name := range names {
+		switch introspection.Scalar(name) {
+		case introspection.ScalarString, introspection.ScalarInt,
+			introspection.ScalarFloat, introspection.ScalarBoolean, introspection.ScalarID:
+			continue
+		}
+		if _, _, ok := templates.MappedScalar(name); ok {
+			continue
+		}
+		if templates.IsAnyScalar(name) {
+			warnings = append(warnings, fmt.Sprintf("scalar %s has no Go mapping, formatting it as any", name))
+			continue
+		}
+		if t := schema.Types.Get(name); t == nil || t.Kind != introspection.TypeKindScalar {
+			return nil, fmt.Errorf("scalar %s is referenced but not defined in the schema", name)
+		}
+	}
+	return warnings, nil
+}
+
+// checkTypeOverrides makes sure the objects overridden with TypeOverrides
+// are mapped to types of the generated package: the generated code builds
+// them from a query selection, setting their unexported fields.
+func checkTypeOverrides(schema *introspection.Schema) error {
+	names := make([]string, 0, len(generator.GetConfig().TypeOverrides))
+	for name := range generator.GetConfig().TypeOverrides {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		t := schema.Types.Get(name)
+		if t == nil || t.Kind != introspection.TypeKindObject {
+			continue
+		}
+		if typ, importPath, _ := templates.OverriddenType(name); importPath != "" {
+			return fmt.Errorf("object %s is overridden by %s from another package, only input objects can be", name, typ)
+		}
+	}
+	return nil
+}
+
+// walkTypeRefs calls fn for every type reference of the fields, arguments
+// and input fields of the schema, including the wrapped ones.
+func walkTypeRefs(schema *introspection.Schema, fn func(*introspection.TypeRef)) {
+	walk := func(r *introspection.TypeRef) {
+		for ref := r; ref != nil; ref = ref.OfType {
+			fn(ref)
+		}
+	}
+	for _, t := range schema.Types {
+		for _, f := range t.Fields {
+			walk(f.TypeRef)
+			for _, arg := range f.Args {
+				walk(arg.TypeRef)
+			}
+		}
+		for _, f := range t.InputFields {
+			walk(f.TypeRef)
+		}
+	}
+}
+
+// usesType returns true if any field, argument or input field of the
+// schema references the given type.
+func usesType(schema *introspection.Schema, name string) bool {
+	used := false
+	walkTypeRefs(schema, func(ref *introspection.TypeRef) {
+		if ref.Name == name {
+			used = true
+		}
+	})
+	return used
+}
@@ -0,0 +1,280 @@
+package gogenerator
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dagger/dagger/codegen/generator"
+	"github.com/dagger/dagger/codegen/introspection"
+)
+
+func TestFileName(t *testing.T) {
+	id := &introspection.Type{Kind: introspection.TypeKindScalar, Name: "ContainerID"}
+	input := &introspection.Type{Kind: introspection.TypeKindInputObject, Name: "BuildArg"}
+
+	require.Equal(t, DefaultFileName, fileName(id, generator.SplitNone))
+	require.Equal(t, "scalars.gen.go", fileName(id, generator.SplitByKind))
+	require.Equal(t, "inputs.gen.go", fileName(input, generator.SplitByKind))
+	require.Equal(t, "container_id.gen.go", fileName(id, generator.SplitByType))
+	require.Equal(t, "build_arg.gen.go", fileName(input, generator.SplitByType))
+}
+
+func TestPruneImports(t *testing.T) {
+	src := `package dagger
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/Khan/genqlient/graphql"
+)
+
+type Label struct {
+	c graphql.Client
+}
+
+func (l *Label) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &struct{}{})
+}
+`
+	pruned, err := pruneImports([]byte(src))
+	require.NoError(t, err)
+	require.Contains(t, string(pruned), "import (\n\t\"encoding/json\"\n\n\t\"github.com/Khan/genqlient/graphql\"\n)")
+	require.NotContains(t, string(pruned), `"context"`)
+
+	pruned, err = pruneImports([]byte("package dagger\n\nimport (\n\t\"context\"\n)\n\ntype ContainerID string\n"))
+	require.NoError(t, err)
+	require.Equal(t, "package dagger\n\ntype ContainerID string\n", string(pruned))
+}
+
+func TestTypeOverrides(t *testing.T) {
+	schema := &introspection.Schema{Types: introspection.Types{
+		{Kind: introspection.TypeKindObject, Name: "Container"},
+		{Kind: introspection.TypeKindInputObject, Name: "BuildArg"},
+	}}
+	t.Cleanup(func() {
+		generator.SetSchema(nil)
+		generator.SetConfig(generator.Config{})
+	})
+
+	generate := func(overrides map[string]string) error {
+		_, err := (&GoGenerator{Config: generator.Config{Package: "dagger", TypeOverrides: overrides}}).
+			Generate(context.Background(), schema)
+		return err
+	}
+	require.NoError(t, generate(map[string]string{"BuildArg": "example.com/build.Arg", "Container": "MyContainer"}))
+	require.ErrorContains(t, generate(map[string]string{"Container": "*example.com/build.Container"}),
+		"object Container is overridden by *build.Container from another package")
+}
+
+func TestScalarWarnings(t *testing.T) {
+	schema := &introspection.Schema{Types: introspection.Types{
+		{Kind: introspection.TypeKindScalar, Name: "Platform"},
+		{Kind: introspection.TypeKindObject, Name: "Container", Fields: []*introspection.Field{
+			{Name: "platform", TypeRef: &introspection.TypeRef{Kind: introspection.TypeKindScalar, Name: "Platform"}},
+		}},
+	}}
+	generator.SetSchemaParents(schema)
+	t.Cleanup(func() {
+		generator.SetSchema(nil)
+		generator.SetConfig(generator.Config{})
+	})
+
+	gen := &GoGenerator{Config: generator.Config{Package: "dagger"}}
+	_, err := gen.Generate(context.Background(), schema)
+	require.NoError(t, err)
+	require.Empty(t, gen.Warnings)
+
+	gen = &GoGenerator{Config: generator.Config{Package: "dagger", UnknownScalarsAsAny: true}}
+	_, err = gen.Generate(context.Background(), schema)
+	require.NoError(t, err)
+	require.Equal(t, []string{"scalar Platform has no Go mapping, formatting it as any"}, gen.Warnings)
+}
+
+// TestTypeNameAffixes builds two clients generated into the same package
+// with different affixes, along with the real query builder.
+func TestTypeNameAffixes(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds the generated code")
+	}
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go isn't installed")
+	}
+
+	ref := func(kind introspection.TypeKind, name string) *introspection.TypeRef {
+		return &introspection.TypeRef{Kind: introspection.TypeKindNonNull, OfType: &introspection.TypeRef{Kind: kind, Name: name}}
+	}
+	schema := &introspection.Schema{Types: introspection.Types{
+		{Kind: introspection.TypeKindObject, Name: "Query", Fields: []*introspection.Field{
+			{Name: "container", TypeRef: ref(introspection.TypeKindObject, "Container"), Args: introspection.InputValues{
+				{Name: "platform", TypeRef: &introspection.TypeRef{Kind: introspection.TypeKindScalar, Name: "Platform"}},
+			}},
+			{Name: "defaultPlatform", TypeRef: ref(introspection.TypeKindScalar, "Platform")},
+		}},
+		{Kind: introspection.TypeKindObject, Name: "Container", Fields: []*introspection.Field{
+			{Name: "id", TypeRef: ref(introspection.TypeKindScalar, "ContainerID")},
+			{Name: "build", TypeRef: ref(introspection.TypeKindObject, "Container"), Args: introspection.InputValues{
+				{Name: "buildArgs", TypeRef: &introspection.TypeRef{Kind: introspection.TypeKindList, OfType: ref(introspection.TypeKindInputObject, "BuildArg")}},
+			}},
+			{Name: "withExposedPort", TypeRef: ref(introspection.TypeKindObject, "Container"), Args: introspection.InputValues{
+				{Name: "protocol", TypeRef: ref(introspection.TypeKindEnum, "NetworkProtocol")},
+			}},
+			{Name: "sync", TypeRef: ref(introspection.TypeKindScalar, "ContainerID")},
+		}},
+		{Kind: introspection.TypeKindInputObject, Name: "BuildArg", InputFields: introspection.InputValues{
+			{Name: "name", TypeRef: ref(introspection.TypeKindScalar, string(introspection.ScalarString))},
+		}},
+		{Kind: introspection.TypeKindEnum, Name: "NetworkProtocol", EnumValues: []introspection.EnumValue{{Name: "TCP"}, {Name: "UDP"}}},
+		{Kind: introspection.TypeKindScalar, Name: "ContainerID"},
+		{Kind: introspection.TypeKindScalar, Name: "Platform"},
+	}}
+	generator.SetSchemaParents(schema)
+	t.Cleanup(func() {
+		generator.SetSchema(nil)
+		generator.SetConfig(generator.Config{})
+	})
+
+	dir := t.TempDir()
+	for _, prefix := range []string{"Github", "Gitlab"} {
+		src, err := (&GoGenerator{Config: generator.Config{Package: "dagger", TypeNamePrefix: prefix}}).
+			Generate(context.Background(), schema)
+		require.NoError(t, err)
+		require.Contains(t, string(src), "func (r *"+prefix+"Client) Container(opts ..."+prefix+"ContainerOpts) *"+prefix+"Container {")
+		require.NoError(t, os.WriteFile(filepath.Join(dir, prefix+".gen.go"), src, 0o600))
+	}
+
+	// The clients are declared by the SDK.
+	clients := `package dagger
+
+import (
+	"github.com/Khan/genqlient/graphql"
+
+	"dagger.io/dagger/internal/querybuilder"
+)
+
+type GithubClient struct {
+	q *querybuilder.Selection
+	c graphql.Client
+}
+
+type GitlabClient struct {
+	q *querybuilder.Selection
+	c graphql.Client
+}
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "clients.go"), []byte(clients), 0o600))
+
+	vetModule(t, goBin, dir, "")
+}
+
+// TestVersionedImports builds a client using types of packages whose import
+// paths end with a major version, replaced by local modules.
+func TestVersionedImports(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds the generated code")
+	}
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go isn't installed")
+	}
+
+	ref := func(kind introspection.TypeKind, name string) *introspection.TypeRef {
+		return &introspection.TypeRef{Kind: introspection.TypeKindNonNull, OfType: &introspection.TypeRef{Kind: kind, Name: name}}
+	}
+	schema := &introspection.Schema{Types: introspection.Types{
+		{Kind: introspection.TypeKindObject, Name: "Container", Fields: []*introspection.Field{
+			{Name: "config", TypeRef: ref(introspection.TypeKindScalar, string(introspection.ScalarString))},
+			{Name: "label", TypeRef: ref(introspection.TypeKindScalar, string(introspection.ScalarString))},
+			{Name: "build", TypeRef: ref(introspection.TypeKindObject, "Container"), Args: introspection.InputValues{
+				{Name: "buildArgs", TypeRef: &introspection.TypeRef{Kind: introspection.TypeKindList, OfType: ref(introspection.TypeKindInputObject, "BuildArg")}},
+			}},
+		}},
+		{Kind: introspection.TypeKindInputObject, Name: "BuildArg", InputFields: introspection.InputValues{
+			{Name: "value", TypeRef: ref(introspection.TypeKindScalar, string(introspection.ScalarString))},
+		}},
+	}}
+	generator.SetSchemaParents(schema)
+	t.Cleanup(func() {
+		generator.SetSchema(nil)
+		generator.SetConfig(generator.Config{})
+	})
+
+	src, err := (&GoGenerator{Config: generator.Config{Package: "dagger", FieldTypeOverrides: map[string]string{
+		"Container.config": "gopkg.in/yaml.v3.Node",
+		"Container.label":  "*example.com/bar/v2.Baz",
+		"BuildArg.value":   "example.com/bar/v2.Baz",
+	}}}).Generate(context.Background(), schema)
+	require.NoError(t, err)
+	require.Contains(t, string(src), "\tbar \"example.com/bar/v2\"\n")
+	require.Contains(t, string(src), "\tyaml \"gopkg.in/yaml.v3\"\n")
+	require.Contains(t, string(src), "Value bar.Baz")
+	require.Contains(t, string(src), "func (r *Container) Config(ctx context.Context) (yaml.Node, error) {")
+	require.Contains(t, string(src), "func (r *Container) Label(ctx context.Context) (*bar.Baz, error) {")
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "dagger.gen.go"), src, 0o600))
+	// The local modules replacing the packages, by directory.
+	stubs := map[string]struct{ module, source string }{
+		"bar":  {"example.com/bar/v2", "package bar\n\ntype Baz struct{ Name string }\n"},
+		"yaml": {"gopkg.in/yaml.v3", "package yaml\n\ntype Node struct{ Value string }\n"},
+	}
+	for name, stub := range stubs {
+		stubDir := filepath.Join(dir, "stubs", name)
+		require.NoError(t, os.MkdirAll(stubDir, 0o700))
+		require.NoError(t, os.WriteFile(filepath.Join(stubDir, "go.mod"), []byte("module "+stub.module+"\n"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(stubDir, name+".go"), []byte(stub.source), 0o600))
+	}
+
+	vetModule(t, goBin, dir, `
+require example.com/bar/v2 v2.0.0
+
+replace (
+	example.com/bar/v2 => ./stubs/bar
+	gopkg.in/yaml.v3 => ./stubs/yaml
+)
+`)
+}
+
+// vetModule vets the generated code in dir, written in a module requiring
+// the dependencies of the SDK along with extraGoMod, with a copy of the
+// real query builder.
+func vetModule(t *testing.T, goBin, dir, extraGoMod string) {
+	t.Helper()
+
+	sdk := filepath.Join("..", "..", "..", "sdk", "go")
+	goMod, err := os.ReadFile(filepath.Join(sdk, "go.mod"))
+	require.NoError(t, err)
+	goMod = regexp.MustCompile(`(?m)^replace .*$`).ReplaceAll(goMod, nil)
+	goMod = append(goMod, extraGoMod...)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), goMod, 0o600))
+	goSum, err := os.ReadFile(filepath.Join(sdk, "go.sum"))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.sum"), goSum, 0o600))
+
+	qbDir := filepath.Join(dir, "internal", "querybuilder")
+	require.NoError(t, os.MkdirAll(qbDir, 0o700))
+	qbFiles, err := filepath.Glob(filepath.Join(sdk, "internal", "querybuilder", "*.go"))
+	require.NoError(t, err)
+	for _, name := range qbFiles {
+		if strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		contents, err := os.ReadFile(name)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(qbDir, filepath.Base(name)), contents, 0o600))
+	}
+
+	cmd := exec.Command(goBin, "vet", ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOTOOLCHAIN=local", "GOWORK=off")
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+}
@@ -0,0 +1,152 @@
+package templates
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dagger/dagger/codegen/generator"
+	"github.com/dagger/dagger/codegen/introspection"
+)
+
+// deepCopyFields returns the statements of the DeepCopy method of an
+// object or input object, cloning its fields from `r` into `out`, which is
+// a shallow copy of `r`. Objects only hold their selection, which is
+// immutable, and their cached fields.
+func deepCopyFields(t *introspection.Type) string {
+	var stmts []string
+	for _, f := range t.Fields {
+		if !f.TypeRef.IsScalar() {
+			continue
+		}
+		name := escapeIdentifier(f.Name)
+		stmts = append(stmts, deepCopy("out."+name, "r."+name, "*"+formatFieldType(*f), 0)...)
+	}
+	for _, f := range t.InputFields {
+		name := formatFieldName(f.Name, f.TypeRef)
+		stmts = append(stmts, deepCopy("out."+name, "r."+name, formatInputFieldType(f), 0)...)
+	}
+	return strings.Join(stmts, "\n")
+}
+
+// deepCopy returns the statements cloning src into dst, which already holds
+// a shallow copy of src, according to their Go type. The types unknown to
+// the generator, like interfaces or overridden types, are left as-is.
+// Example: `out.Tags`, `r.Tags`, `[]string` ->
+//
+//	if r.Tags != nil {
+//		out.Tags = make([]string, len(r.Tags))
+//		copy(out.Tags, r.Tags)
+//	}
+func deepCopy(dst, src, typ string, depth int) []string {
+	switch {
+	case isGeneratedStruct(typ):
+		return []string{fmt.Sprintf("%s = *%s.DeepCopy()", dst, operand(src))}
+
+	case strings.HasPrefix(typ, "*"):
+		elem := typ[1:]
+		if isGeneratedStruct(elem) {
+			return []string{fmt.Sprintf("%s = %s.DeepCopy()", dst, src)}
+		}
+		if elem == "big.Rat" {
+			return []string{fmt.Sprintf("if %s != nil {\n%s = new(big.Rat).Set(%s)\n}", src, dst, src)}
+		}
+		v := fmt.Sprintf("v%d", depth)
+		stmts := []string{fmt.Sprintf("%s := *%s", v, src)}
+		stmts = append(stmts, deepCopy(v, "*"+src, elem, depth+1)...)
+		stmts = append(stmts, fmt.Sprintf("%s = &%s", dst, v))
+		return []string{fmt.Sprintf("if %s != nil {\n%s\n}", src, strings.Join(stmts, "\n"))}
+
+	case strings.HasPrefix(typ, "[]") || isBytes(typ):
+		elem := "byte"
+		if strings.HasPrefix(typ, "[]") {
+			elem = typ[2:]
+		}
+		stmts := []string{
+			fmt.Sprintf("%s = make(%s, len(%s))", dst, typ, src),
+			fmt.Sprintf("copy(%s, %s)", dst, src),
+		}
+		i := fmt.Sprintf("i%d", depth)
+		if elemStmts := deepCopy(dst+"["+i+"]", operand(src)+"["+i+"]", elem, depth+1); len(elemStmts) > 0 {
+			stmts = append(stmts, fmt.Sprintf("for %s := range %s {\n%s\n}", i, src, strings.Join(elemStmts, "\n")))
+		}
+		return []string{fmt.Sprintf("if %s != nil {\n%s\n}", src, strings.Join(stmts, "\n"))}
+
+	case strings.HasPrefix(typ, "map["):
+		key, elem, ok := splitMapType(typ)
+		if !ok {
+			return nil
+		}
+		k, v, c := fmt.Sprintf("k%d", depth), fmt.Sprintf("v%d", depth), fmt.Sprintf("c%d", depth)
+		loop := []string{fmt.Sprintf("%s := %s", c, v)}
+		loop = append(loop, deepCopy(c, v, elem, depth+1)...)
+		loop = append(loop, fmt.Sprintf("%s[%s] = %s", dst, k, c))
+		return []string{fmt.Sprintf("if %s != nil {\n%s = make(map[%s]%s, len(%s))\nfor %s, %s := range %s {\n%s\n}\n}",
+			src, dst, key, elem, src, k, v, src, strings.Join(loop, "\n"))}
+	}
+
+	// Values (e.g. `string`, `time.Time`, enums) are copied by assignment.
+	return nil
+}
+
+// operand parenthesizes a dereferenced expression so that it can be
+// indexed or have its methods called.
+// Example: `*r.Tags` -> `(*r.Tags)`
+func operand(expr string) string {
+	if strings.HasPrefix(expr, "*") {
+		return "(" + expr + ")"
+	}
+	return expr
+}
+
+// isGeneratedStruct returns true if a Go type is a generated object or
+// input object, which have a DeepCopy method.
+func isGeneratedStruct(typ string) bool {
+	return generatedType(typ) != nil
+}
+
+// generatedType returns the object or input object a Go type is generated
+// for, if any.
+func generatedType(typ string) *introspection.Type {
+	schema := generator.GetSchema()
+	if schema == nil {
+		return nil
+	}
+	for _, t := range schema.Types {
+		if t.Kind != introspection.TypeKindObject && t.Kind != introspection.TypeKindInputObject {
+			continue
+		}
+		if t.Name == generator.QueryStructName {
+			continue
+		}
+		if _, _, ok := OverriddenType(t.Name); ok {
+			continue
+		}
+		if formatTypeName(t.Name) == typ {
+			return t
+		}
+	}
+	return nil
+}
+
+// isBytes returns true if a Go type is a named slice of bytes.
+func isBytes(typ string) bool {
+	return typ == "json.RawMessage"
+}
+
+// splitMapType splits a map type into its key and element types.
+// Example: `map[string][]int` -> `string`, `[]int`
+func splitMapType(typ string) (key, elem string, ok bool) {
+	depth := 0
+	for i, c := range typ {
+		switch c {
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				return typ[len("map["):i], typ[i+1:], true
+			}
+		}
+	}
+	return "", "", false
+}
@@ -0,0 +1,131 @@
+package templates
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dagger/dagger/codegen/generator"
+	"github.com/dagger/dagger/codegen/introspection"
+)
+
+func TestSplitMapType(t *testing.T) {
+	key, elem, ok := splitMapType("map[string][]int")
+	require.True(t, ok)
+	require.Equal(t, "string", key)
+	require.Equal(t, "[]int", elem)
+
+	key, elem, ok = splitMapType("map[[2]int]map[string]bool")
+	require.True(t, ok)
+	require.Equal(t, "[2]int", key)
+	require.Equal(t, "map[string]bool", elem)
+}
+
+// TestDeepCopy builds the generated input objects along with a test
+// mutating their copies.
+func TestDeepCopy(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds the generated code")
+	}
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go isn't installed")
+	}
+
+	input := func(name string) *introspection.TypeRef {
+		return &introspection.TypeRef{Kind: introspection.TypeKindInputObject, Name: name}
+	}
+	filter := &introspection.Type{
+		Kind: introspection.TypeKindInputObject,
+		Name: "Filter",
+		InputFields: introspection.InputValues{
+			{Name: "tags", TypeRef: listOf(nonNull(scalar(introspection.ScalarString)))},
+			{Name: "not", TypeRef: input("Filter")},
+			{Name: "and", TypeRef: listOf(nonNull(input("Filter")))},
+			{Name: "range", TypeRef: nonNull(input("Range"))},
+			{Name: "meta", TypeRef: scalar("JSON")},
+			{Name: "labels", TypeRef: scalar(introspection.ScalarString)},
+		},
+	}
+	rng := &introspection.Type{
+		Kind: introspection.TypeKindInputObject,
+		Name: "Range",
+		InputFields: introspection.InputValues{
+			{Name: "bounds", TypeRef: nonNull(listOf(nonNull(scalar(introspection.ScalarInt))))},
+		},
+	}
+	schema := &introspection.Schema{Types: introspection.Types{filter, rng}}
+	generator.SetSchemaParents(schema)
+	generator.SetSchema(schema)
+	generator.SetConfig(generator.Config{
+		DeepCopy:           true,
+		FieldTypeOverrides: map[string]string{"Filter.labels": "map[string][]string"},
+	})
+	t.Cleanup(func() {
+		generator.SetSchema(nil)
+		generator.SetConfig(generator.Config{})
+	})
+
+	src := bytes.NewBufferString("package deepcopy\n\nimport \"encoding/json\"\n")
+	for _, input := range schema.Types {
+		require.NoError(t, Input.Execute(src, input))
+	}
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod": "module deepcopy\n\ngo 1.20\n",
+		"api.go": src.String(),
+		"api_test.go": `package deepcopy
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDeepCopy(t *testing.T) {
+	meta := json.RawMessage("{}")
+	orig := &Filter{
+		Tags:   []string{"a"},
+		Not:    &Filter{Tags: []string{"b"}},
+		And:    []Filter{{Range: Range{Bounds: []int{1}}}},
+		Range:  Range{Bounds: []int{2}},
+		Meta:   &meta,
+		Labels: map[string][]string{"k": {"v"}},
+	}
+
+	c := orig.DeepCopy()
+	c.Tags[0] = "z"
+	c.Not.Tags[0] = "z"
+	c.And[0].Range.Bounds[0] = 0
+	c.Range.Bounds[0] = 0
+	(*c.Meta)[0] = '['
+	c.Labels["k"][0] = "z"
+
+	switch {
+	case orig.Tags[0] != "a", orig.Not.Tags[0] != "b":
+		t.Fatal("pointers and slices are shared")
+	case orig.And[0].Range.Bounds[0] != 1, orig.Range.Bounds[0] != 2:
+		t.Fatal("nested input objects are shared")
+	case string(*orig.Meta) != "{}", orig.Labels["k"][0] != "v":
+		t.Fatal("scalars and maps are shared")
+	}
+	if (*Filter)(nil).DeepCopy() != nil {
+		t.Fatal("nil isn't copied as nil")
+	}
+}
+`,
+	}
+	for name, contents := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(contents), 0o600))
+	}
+
+	cmd := exec.Command(goBin, "test", "./...")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOTOOLCHAIN=local", "GOWORK=off")
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+}
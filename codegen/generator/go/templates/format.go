@@ -1,16 +1,146 @@
 package templates
 
-import "github.com/dagger/dagger/codegen/generator"
+import (
+	"strings"
+
+	"golang.org/x/mod/semver"
+
+	"github.com/dagger/dagger/codegen/generator"
+	"github.com/dagger/dagger/codegen/introspection"
+)
+
+// defaultIDType is the Go type generated for the GraphQL `ID` scalar when
+// no IDType is configured.
+const defaultIDType = "ID"
+
+var (
+	// DateTimeScalars lists the GraphQL scalars mapped to `time.Time`.
+	DateTimeScalars = []string{"DateTime"}
+
+	// BytesScalars lists the GraphQL scalars mapped to `[]byte`, which are
+	// base64 encoded on the wire.
+	BytesScalars = []string{"Bytes"}
+
+	// JSONScalars lists the GraphQL scalars holding arbitrary JSON payloads,
+	// mapped to `json.RawMessage`.
+	JSONScalars = []string{"JSON"}
+
+	// BigIntScalars lists the GraphQL scalars holding 64-bit integers, since
+	// `Int` is 32-bit only, mapped to `int64`.
+	BigIntScalars = []string{"Long", "BigInt"}
+
+	// DecimalScalars lists the GraphQL scalars holding arbitrary-precision
+	// decimals, mapped to the configured DecimalType.
+	DecimalScalars = []string{"Decimal", "Money"}
+
+	// UploadScalars lists the GraphQL scalars of files uploaded with a
+	// multipart request, mapped to the configured UploadType.
+	UploadScalars = []string{"Upload"}
+)
+
+// defaultDecimalType is the Go type the DecimalScalars are mapped to when
+// no DecimalType is configured.
+const defaultDecimalType = "*math/big.Rat"
+
+// defaultUploadType is the Go type the UploadScalars are mapped to when no
+// UploadType is configured.
+const defaultUploadType = "io.Reader"
 
 // FormatTypeFunc is an implementation of generator.FormatTypeFuncs interface
 // to format GraphQL type into Golang.
-type FormatTypeFunc struct{}
+//
+// Its zero value formats types according to the generator.Config only. The
+// formatter used by the templates is set with SetFormatTypeFunc, so that
+// the generated declarations match the formatted types.
+type FormatTypeFunc struct {
+	// Scalars maps GraphQL scalars to fully-qualified Go types, taking
+	// precedence over the scalars registered with generator.RegisterScalar
+	// and the built-in mappings.
+	Scalars map[string]string
+	// Initialisms lists additional initialisms kept upper-cased in the
+	// generated names, on top of the configured ones.
+	Initialisms []string
+	// NullableListPointers makes nullable lists and list elements pointers,
+	// like the generator.Config option of the same name.
+	NullableListPointers bool
+}
+
+var _ generator.FormatTypeFuncs = &FormatTypeFunc{}
+
+// FormatTypeFuncOption configures a FormatTypeFunc.
+type FormatTypeFuncOption func(f *FormatTypeFunc)
 
+// NewFormatTypeFunc creates a FormatTypeFunc configured with the given
+// options. Without options, it's equivalent to the zero FormatTypeFunc.
+func NewFormatTypeFunc(opts ...FormatTypeFuncOption) *FormatTypeFunc {
+	f := &FormatTypeFunc{}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
+}
+
+// WithScalar maps a GraphQL scalar to a fully-qualified Go type.
+// Example: `URI`, `*net/url.URL`
+func WithScalar(graphqlName, typ string) FormatTypeFuncOption {
+	return func(f *FormatTypeFunc) {
+		if f.Scalars == nil {
+			f.Scalars = map[string]string{}
+		}
+		f.Scalars[graphqlName] = typ
+	}
+}
+
+// WithInitialisms adds initialisms (e.g. `GPU`) kept upper-cased in the
+// generated names.
+func WithInitialisms(initialisms ...string) FormatTypeFuncOption {
+	return func(f *FormatTypeFunc) {
+		f.Initialisms = append(f.Initialisms, initialisms...)
+	}
+}
+
+// WithNullableListPointers makes nullable lists and list elements pointers.
+func WithNullableListPointers() FormatTypeFuncOption {
+	return func(f *FormatTypeFunc) {
+		f.NullableListPointers = true
+	}
+}
+
+// FormatKindList formats lists as slices.
 func (f *FormatTypeFunc) FormatKindList(representation string) string {
 	representation = "[]" + representation
 	return representation
 }
 
+// FormatNullable decides which nullable types are pointers, since unset
+// values are otherwise represented by the zero value:
+//   - with NullableListPointers, nullable lists and list elements, so that
+//     each of `[Int]`, `[Int]!`, `[Int!]` and `[Int!]!` is a distinct Go type.
+//   - input object fields, so that together with `omitempty` unset fields
+//     are left out of the request instead of being sent as zero values.
+//   - with NullableOutputPointers, the scalars returned by fields, so that
+//     null is told apart from the zero value.
+//
+// Optional arguments are set through Opts structs instead, and types which
+// can already be nil are kept as-is.
+func (f *FormatTypeFunc) FormatNullable(representation string, t generator.NullableType) string {
+	switch {
+	case t.Elem || t.Ref.Kind == introspection.TypeKindList:
+		if !f.NullableListPointers && !generator.GetConfig().NullableListPointers || strings.HasPrefix(representation, "*") {
+			return representation
+		}
+		return "*" + representation
+	case t.Usage == generator.UsageInputField:
+	case t.Usage == generator.UsageReturn && generator.GetConfig().NullableOutputPointers && t.Ref.IsScalar():
+	default:
+		return representation
+	}
+	if isNillable(representation) {
+		return representation
+	}
+	return "*" + representation
+}
+
 func (f *FormatTypeFunc) FormatKindScalarString(representation string) string {
 	representation += "string"
 	return representation
@@ -32,26 +162,283 @@ func (f *FormatTypeFunc) FormatKindScalarBoolean(representation string) string {
 }
 
 func (f *FormatTypeFunc) FormatKindScalarDefault(representation string, refName string, input bool) string {
+	// Mapped scalars are the same Go type for inputs and outputs.
+	if typ, importPath, ok := f.mappedScalar(refName); ok {
+		addImport(importPath)
+		representation += typ
+		return representation
+	}
+
+	if IsAnyScalar(refName) {
+		return representation + anyType()
+	}
+
+	// Object IDs are the exception: the object can be passed instead of
+	// its ID as an input, but outputs are the ID itself since that's what
+	// the API returns (see ConvertID).
 	if alias, ok := generator.CustomScalar[refName]; ok && input {
-		representation += "*" + alias
+		representation += "*" + formatTypeName(alias)
 	} else {
-		representation += refName
+		representation += formatTypeName(refName)
 	}
 
 	return representation
 }
 
 func (f *FormatTypeFunc) FormatKindObject(representation string, refName string) string {
-	representation += formatName(refName)
+	if typ, importPath, ok := OverriddenType(refName); ok {
+		addImport(importPath)
+		return representation + typ
+	}
+	representation += formatTypeName(refName)
+	return representation
+}
+
+func (f *FormatTypeFunc) FormatKindInterface(representation string, refName string) string {
+	representation += formatTypeName(refName)
+	return representation
+}
+
+func (f *FormatTypeFunc) FormatKindUnion(representation string, refName string) string {
+	representation += formatTypeName(refName)
 	return representation
 }
 
 func (f *FormatTypeFunc) FormatKindInputObject(representation string, refName string) string {
-	representation += formatName(refName)
+	if typ, importPath, ok := OverriddenType(refName); ok {
+		addImport(importPath)
+		return representation + typ
+	}
+	representation += formatTypeName(refName)
 	return representation
 }
 
 func (f *FormatTypeFunc) FormatKindEnum(representation string, refName string) string {
-	representation += refName
+	representation += affixTypeName(refName)
 	return representation
 }
+
+// MappedScalar returns the Go type a GraphQL scalar is mapped to instead of
+// a generated named type, along with the package to import to use it.
+//
+// The Scalars of the FormatTypeFunc set with SetFormatTypeFunc, then the
+// scalars registered with generator.RegisterScalar take precedence over
+// the built-in mappings (`ID`, DateTimeScalars, BytesScalars, JSONScalars,
+// BigIntScalars, DecimalScalars, UploadScalars).
+func MappedScalar(name string) (typ string, importPath string, ok bool) {
+	return formatTypeFunc.mappedScalar(name)
+}
+
+func (f *FormatTypeFunc) mappedScalar(name string) (typ string, importPath string, ok bool) {
+	if mapped, ok := f.Scalars[name]; ok {
+		typ, importPath := qualifiedType(mapped)
+		return typ, importPath, true
+	}
+	if registered, ok := generator.LookupScalar(name); ok {
+		typ, importPath := qualifiedType(registered)
+		return typ, importPath, true
+	}
+
+	if name == string(introspection.ScalarID) {
+		return idType(), "", true
+	}
+
+	builtins := []struct {
+		names []string
+		typ   string
+	}{
+		{DateTimeScalars, "time.Time"},
+		{BytesScalars, "[]byte"},
+		{JSONScalars, "encoding/json.RawMessage"},
+		{BigIntScalars, "int64"},
+		{DecimalScalars, decimalType()},
+		{UploadScalars, uploadType()},
+	}
+	for _, b := range builtins {
+		if contains(b.names, name) {
+			typ, importPath := qualifiedType(b.typ)
+			return typ, importPath, true
+		}
+	}
+
+	return "", "", false
+}
+
+// IsAnyScalar returns true if a GraphQL scalar is formatted as `any`
+// because it has no mapping and UnknownScalarsAsAny is set.
+func IsAnyScalar(name string) bool {
+	if !generator.GetConfig().UnknownScalarsAsAny {
+		return false
+	}
+	if _, _, ok := MappedScalar(name); ok {
+		return false
+	}
+	_, ok := generator.CustomScalar[name]
+	return !ok
+}
+
+// OverriddenType returns the Go type configured in TypeOverrides for a
+// GraphQL type, along with the package to import to use it.
+func OverriddenType(name string) (typ string, importPath string, ok bool) {
+	override, ok := generator.GetConfig().TypeOverrides[name]
+	if !ok {
+		return "", "", false
+	}
+	typ, importPath = qualifiedType(override)
+	return typ, importPath, true
+}
+
+// OverriddenFieldType returns the Go type configured in FieldTypeOverrides
+// for a field of a GraphQL type, along with the package to import to use it.
+func OverriddenFieldType(parent *introspection.Type, name string) (typ string, importPath string, ok bool) {
+	if parent == nil {
+		return "", "", false
+	}
+	override, ok := generator.GetConfig().FieldTypeOverrides[parent.Name+"."+name]
+	if !ok {
+		return "", "", false
+	}
+	typ, importPath = qualifiedType(override)
+	return typ, importPath, true
+}
+
+// idType returns the Go type the GraphQL `ID` scalar is mapped to.
+func idType() string {
+	if t := generator.GetConfig().IDType; t != "" {
+		return t
+	}
+	return formatTypeName(defaultIDType)
+}
+
+// decimalType returns the fully-qualified Go type the DecimalScalars are
+// mapped to.
+func decimalType() string {
+	if t := generator.GetConfig().DecimalType; t != "" {
+		return t
+	}
+	return defaultDecimalType
+}
+
+// uploadType returns the fully-qualified Go type the UploadScalars are
+// mapped to.
+func uploadType() string {
+	if t := generator.GetConfig().UploadType; t != "" {
+		return t
+	}
+	return defaultUploadType
+}
+
+// IsUploadScalar returns true if a GraphQL scalar is a file to upload, which
+// requires a multipart request: the UploadScalars, unless they're mapped to
+// another Go type, and the scalars mapped to a file type.
+// The Scalars of the FormatTypeFunc set with SetFormatTypeFunc, then the
+// scalars registered with generator.RegisterScalar are checked, like
+// MappedScalar.
+func IsUploadScalar(name string) bool {
+	return formatTypeFunc.isUploadScalar(name)
+}
+
+func (f *FormatTypeFunc) isUploadScalar(name string) bool {
+	mapped, ok := f.Scalars[name]
+	if !ok {
+		mapped, ok = generator.LookupScalar(name)
+	}
+	if ok {
+		return isUploadType(mapped)
+	}
+	return contains(UploadScalars, name)
+}
+
+// isUploadType returns true if a fully-qualified Go type is a file the
+// querybuilder uploads.
+func isUploadType(typ string) bool {
+	switch typ {
+	case defaultUploadType, "*mime/multipart.FileHeader", uploadType():
+		return true
+	}
+	return false
+}
+
+// anyType returns the Go type of arbitrary values.
+func anyType() string {
+	// `any` was introduced as an alias of `interface{}` in Go 1.18.
+	if !TargetsGoVersion("1.18") {
+		return "interface{}"
+	}
+	return "any"
+}
+
+// TargetsGoVersion returns true if the generated code can use the features
+// of a Go version (e.g. `1.18`), which it can't if GoVersion is older.
+func TargetsGoVersion(version string) bool {
+	target := generator.GetConfig().GoVersion
+	if target == "" {
+		return true
+	}
+	return semver.Compare(goSemver(target), goSemver(version)) >= 0
+}
+
+// IsValidGoVersion returns true if a Go version like `1.17`, `1.17.3` or
+// `go1.17` can be targeted.
+func IsValidGoVersion(version string) bool {
+	return semver.IsValid(goSemver(version))
+}
+
+// goSemver converts a Go version into its semantic version.
+// Example: `go1.17` -> `v1.17`
+func goSemver(version string) string {
+	return "v" + strings.TrimPrefix(version, "go")
+}
+
+// GeneratesIDType returns true if the GraphQL `ID` scalar is mapped to a
+// dedicated generated type.
+func GeneratesIDType() bool {
+	typ, _, _ := MappedScalar(string(introspection.ScalarID))
+	return typ == formatTypeName(defaultIDType)
+}
+
+func contains(s []string, v string) bool {
+	for _, i := range s {
+		if i == v {
+			return true
+		}
+	}
+	return false
+}
+
+// qualifiedType splits a fully-qualified Go type into the type as it's
+// referenced in the generated code and the package to import, see
+// packageName.
+// Example: `*net/url.URL` -> `*url.URL`, `net/url`
+func qualifiedType(s string) (typ string, importPath string) {
+	var prefix string
+	for strings.HasPrefix(s, "*") || strings.HasPrefix(s, "[]") {
+		p := s[:1]
+		if p == "[" {
+			p = s[:2]
+		}
+		prefix += p
+		s = s[len(p):]
+	}
+
+	i := strings.LastIndex(s, ".")
+	if i < 0 {
+		return prefix + s, ""
+	}
+	importPath = s[:i]
+	return prefix + packageName(importPath) + s[i:], importPath
+}
+
+// isNillable returns true if the zero value of a Go type is nil. Named
+// interfaces can't be told apart, except the UploadType.
+func isNillable(typ string) bool {
+	if upload, _ := qualifiedType(uploadType()); typ == "any" || typ == upload {
+		return true
+	}
+	for _, prefix := range []string{"*", "[]", "map[", "chan ", "func(", "interface{"} {
+		if strings.HasPrefix(typ, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,502 @@
+package templates
+
+import (
+	"bytes"
+	"go/parser"
+	"go/token"
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dagger/dagger/codegen/generator"
+	"github.com/dagger/dagger/codegen/introspection"
+)
+
+func listOf(r *introspection.TypeRef) *introspection.TypeRef {
+	return &introspection.TypeRef{Kind: introspection.TypeKindList, OfType: r}
+}
+
+func nonNull(r *introspection.TypeRef) *introspection.TypeRef {
+	return &introspection.TypeRef{Kind: introspection.TypeKindNonNull, OfType: r}
+}
+
+func scalar(name introspection.Scalar) *introspection.TypeRef {
+	return &introspection.TypeRef{Kind: introspection.TypeKindScalar, Name: string(name)}
+}
+
+func TestFormatKindList(t *testing.T) {
+	cases := []struct {
+		name string
+		ref  *introspection.TypeRef
+		want string
+	}{
+		{
+			name: "[Int]",
+			ref:  listOf(scalar(introspection.ScalarInt)),
+			want: "[]int",
+		},
+		{
+			name: "[[Int]]",
+			ref:  listOf(listOf(scalar(introspection.ScalarInt))),
+			want: "[][]int",
+		},
+		{
+			name: "[[[String]]]",
+			ref:  listOf(listOf(listOf(scalar(introspection.ScalarString)))),
+			want: "[][][]string",
+		},
+		{
+			name: "[[String!]!]!",
+			ref:  nonNull(listOf(nonNull(listOf(nonNull(scalar(introspection.ScalarString)))))),
+			want: "[][]string",
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			require.Equal(t, c.want, commonFunc.FormatOutputType(c.ref))
+			require.Equal(t, c.want, commonFunc.FormatInputType(c.ref))
+		})
+	}
+}
+
+func TestNullableListPointers(t *testing.T) {
+	generator.SetConfig(generator.Config{NullableListPointers: true})
+	t.Cleanup(func() { generator.SetConfig(generator.Config{}) })
+
+	cases := []struct {
+		name string
+		ref  *introspection.TypeRef
+		want string
+	}{
+		{
+			name: "[Int!]!",
+			ref:  nonNull(listOf(nonNull(scalar(introspection.ScalarInt)))),
+			want: "[]int",
+		},
+		{
+			name: "[Int]!",
+			ref:  nonNull(listOf(scalar(introspection.ScalarInt))),
+			want: "[]*int",
+		},
+		{
+			name: "[Int!]",
+			ref:  listOf(nonNull(scalar(introspection.ScalarInt))),
+			want: "*[]int",
+		},
+		{
+			name: "[Int]",
+			ref:  listOf(scalar(introspection.ScalarInt)),
+			want: "*[]*int",
+		},
+		{
+			name: "[[Int!]]!",
+			ref:  nonNull(listOf(listOf(nonNull(scalar(introspection.ScalarInt))))),
+			want: "[]*[]int",
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			require.Equal(t, c.want, commonFunc.FormatOutputType(c.ref))
+			require.Equal(t, c.want, commonFunc.FormatInputType(c.ref))
+		})
+	}
+
+	// Elements already formatted as pointers aren't wrapped twice.
+	require.Equal(t, "[]*Container", commonFunc.FormatInputType(nonNull(listOf(scalar("ContainerID")))))
+
+	require.Equal(t, "[]*Label", formatArrayType("*[]*Label"))
+	require.Equal(t, "&Label", formatArrayToSingleType("*[]*Label"))
+	require.Equal(t, "Label", formatArrayToSingleType("[]Label"))
+}
+
+func TestFormatNullable(t *testing.T) {
+	field := func(ref *introspection.TypeRef) introspection.Field {
+		return introspection.Field{Name: "f", TypeRef: ref, ParentObject: &introspection.Type{Name: "Container"}}
+	}
+
+	// Optional arguments are set through Opts structs.
+	require.Equal(t, "string", commonFunc.FormatInputType(scalar(introspection.ScalarString)))
+	require.Equal(t, "time.Time", commonFunc.FormatInputType(scalar("DateTime")))
+	require.Equal(t, "*string", commonFunc.FormatInputFieldType(scalar(introspection.ScalarString)))
+	require.Equal(t, "string", commonFunc.FormatInputFieldType(nonNull(scalar(introspection.ScalarString))))
+	require.Equal(t, "[]byte", commonFunc.FormatInputFieldType(scalar("Bytes")))
+	require.Equal(t, "[]string", commonFunc.FormatInputFieldType(listOf(scalar(introspection.ScalarString))))
+	require.Equal(t, "string", commonFunc.FormatReturnType(field(scalar(introspection.ScalarString))))
+
+	generator.SetConfig(generator.Config{NullableOutputPointers: true})
+	t.Cleanup(func() { generator.SetConfig(generator.Config{}) })
+	require.Equal(t, "*string", commonFunc.FormatReturnType(field(scalar(introspection.ScalarString))))
+	require.Equal(t, "string", commonFunc.FormatReturnType(field(nonNull(scalar(introspection.ScalarString)))))
+	require.Equal(t, "[]string", commonFunc.FormatReturnType(field(listOf(scalar(introspection.ScalarString)))))
+	require.Equal(t, "string", commonFunc.FormatOutputType(scalar(introspection.ScalarString)))
+}
+
+func TestFormatKindScalarDefault(t *testing.T) {
+	cases := []struct {
+		name     string
+		register map[string]string
+		ref      *introspection.TypeRef
+		input    string
+		output   string
+	}{
+		{
+			name:   "unknown scalar",
+			ref:    scalar("Platform"),
+			input:  "*Platform",
+			output: "Platform",
+		},
+		{
+			name:   "object ID",
+			ref:    nonNull(scalar("ContainerID")),
+			input:  "*Container",
+			output: "ContainerID",
+		},
+		{
+			name:   "mapped scalar",
+			ref:    scalar("DateTime"),
+			input:  "*time.Time",
+			output: "time.Time",
+		},
+		{
+			name:     "registered object ID",
+			register: map[string]string{"ContainerID": "string"},
+			ref:      scalar("ContainerID"),
+			input:    "*string",
+			output:   "string",
+		},
+		{
+			name:     "registered nillable",
+			register: map[string]string{"Labels": "map[string]string"},
+			ref:      listOf(scalar("Labels")),
+			input:    "[]map[string]string",
+			output:   "[]map[string]string",
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			t.Cleanup(generator.ResetScalars)
+			for name, typ := range c.register {
+				generator.RegisterScalar(name, typ)
+			}
+
+			require.Equal(t, c.input, commonFunc.FormatInputFieldType(c.ref))
+			require.Equal(t, c.output, commonFunc.FormatOutputType(c.ref))
+		})
+	}
+}
+
+func TestTypeOverrides(t *testing.T) {
+	generator.SetConfig(generator.Config{TypeOverrides: map[string]string{
+		"BuildArg": "example.com/build.Arg",
+		"Label":    "Tag",
+	}})
+	t.Cleanup(func() { generator.SetConfig(generator.Config{}) })
+
+	input := func(name string) *introspection.TypeRef {
+		return &introspection.TypeRef{Kind: introspection.TypeKindInputObject, Name: name}
+	}
+	object := func(name string) *introspection.TypeRef {
+		return &introspection.TypeRef{Kind: introspection.TypeKindObject, Name: name}
+	}
+
+	require.Equal(t, "[]build.Arg", commonFunc.FormatInputType(listOf(nonNull(input("BuildArg")))))
+	require.Equal(t, "Tag", commonFunc.FormatOutputType(object("Label")))
+	require.Equal(t, "Container", commonFunc.FormatOutputType(object("Container")))
+
+	typ, importPath, ok := OverriddenType("BuildArg")
+	require.True(t, ok)
+	require.Equal(t, "build.Arg", typ)
+	require.Equal(t, "example.com/build", importPath)
+}
+
+func TestUploadScalars(t *testing.T) {
+	ResetImports()
+	t.Cleanup(ResetImports)
+
+	// Interfaces can already be nil, so they aren't passed as pointers.
+	require.Equal(t, "io.Reader", commonFunc.FormatInputType(scalar("Upload")))
+	require.Equal(t, "io.Reader", formatInputFieldType(introspection.InputValue{Name: "file", TypeRef: scalar("Upload")}))
+	require.Equal(t, []string{"io"}, Imports())
+
+	generator.SetConfig(generator.Config{UploadType: "*mime/multipart.FileHeader"})
+	t.Cleanup(func() { generator.SetConfig(generator.Config{}) })
+	require.Equal(t, "*multipart.FileHeader", commonFunc.FormatInputType(nonNull(scalar("Upload"))))
+
+	// The scalars mapped by the FormatTypeFunc are uploads if they're
+	// mapped to files.
+	require.True(t, IsUploadScalar("Upload"))
+	require.False(t, IsUploadScalar("File"))
+	SetFormatTypeFunc(NewFormatTypeFunc(
+		WithScalar("File", "*mime/multipart.FileHeader"),
+		WithScalar("Upload", "string"),
+	))
+	t.Cleanup(func() { SetFormatTypeFunc(nil) })
+	require.True(t, IsUploadScalar("File"))
+	require.False(t, IsUploadScalar("Upload"))
+}
+
+func TestBigIntScalars(t *testing.T) {
+	require.Equal(t, "BigInteger", commonFunc.FormatOutputType(scalar("BigInteger")))
+
+	orig := BigIntScalars
+	BigIntScalars = append(BigIntScalars, "BigInteger")
+	t.Cleanup(func() { BigIntScalars = orig })
+
+	require.Equal(t, "int64", commonFunc.FormatOutputType(scalar("BigInteger")))
+	require.Equal(t, "*int64", commonFunc.FormatInputFieldType(scalar("BigInteger")))
+}
+
+func TestMappedScalar(t *testing.T) {
+	cases := []struct {
+		name     string
+		register map[string]string
+		ref      *introspection.TypeRef
+		input    string
+		output   string
+	}{
+		{
+			name:   "DateTime",
+			ref:    scalar("DateTime"),
+			input:  "*time.Time",
+			output: "time.Time",
+		},
+		{
+			name:   "Bytes",
+			ref:    nonNull(scalar("Bytes")),
+			input:  "[]byte",
+			output: "[]byte",
+		},
+		{
+			name:   "JSON",
+			ref:    scalar("JSON"),
+			input:  "*json.RawMessage",
+			output: "json.RawMessage",
+		},
+		{
+			name:     "registered JSON",
+			register: map[string]string{"JSON": "map[string]interface{}"},
+			ref:      scalar("JSON"),
+			input:    "map[string]interface{}",
+			output:   "map[string]interface{}",
+		},
+		{
+			name:   "Long",
+			ref:    scalar("Long"),
+			input:  "*int64",
+			output: "int64",
+		},
+		{
+			name:   "BigInt",
+			ref:    nonNull(scalar("BigInt")),
+			input:  "int64",
+			output: "int64",
+		},
+		{
+			name:   "Decimal",
+			ref:    scalar("Decimal"),
+			input:  "*big.Rat",
+			output: "*big.Rat",
+		},
+		{
+			name:   "Money",
+			ref:    nonNull(scalar("Money")),
+			input:  "*big.Rat",
+			output: "*big.Rat",
+		},
+		{
+			name:     "registered",
+			register: map[string]string{"URI": "net/url.URL"},
+			ref:      scalar("URI"),
+			input:    "*url.URL",
+			output:   "url.URL",
+		},
+		{
+			name:     "registered overrides built-in",
+			register: map[string]string{"Bytes": "string"},
+			ref:      scalar("Bytes"),
+			input:    "*string",
+			output:   "string",
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			t.Cleanup(generator.ResetScalars)
+			for name, typ := range c.register {
+				generator.RegisterScalar(name, typ)
+			}
+
+			require.Equal(t, c.input, commonFunc.FormatInputFieldType(c.ref))
+			require.Equal(t, c.output, commonFunc.FormatOutputType(c.ref))
+		})
+	}
+}
+
+func TestDecimalType(t *testing.T) {
+	generator.SetConfig(generator.Config{DecimalType: "github.com/shopspring/decimal.Decimal"})
+	ResetImports()
+	t.Cleanup(func() {
+		generator.SetConfig(generator.Config{})
+		ResetImports()
+	})
+
+	require.Equal(t, "decimal.Decimal", commonFunc.FormatOutputType(scalar("Decimal")))
+	require.Equal(t, "*decimal.Decimal", commonFunc.FormatInputFieldType(scalar("Decimal")))
+	require.Equal(t, []string{"github.com/shopspring/decimal"}, Imports())
+}
+
+func TestImports(t *testing.T) {
+	ResetImports()
+	t.Cleanup(ResetImports)
+
+	commonFunc.FormatOutputType(scalar(introspection.ScalarString))
+	require.Empty(t, Imports())
+
+	commonFunc.FormatInputType(listOf(scalar("JSON")))
+	commonFunc.FormatOutputType(scalar("DateTime"))
+	commonFunc.FormatOutputType(scalar("DateTime"))
+	require.Equal(t, []string{"encoding/json", "time"}, Imports())
+
+	ResetImports()
+	require.Empty(t, Imports())
+}
+
+func TestQualifiedType(t *testing.T) {
+	cases := []struct {
+		input      string
+		typ        string
+		importPath string
+		imported   string
+	}{
+		{input: "string", typ: "string"},
+		{input: "*net/url.URL", typ: "*url.URL", importPath: "net/url", imported: `"net/url"`},
+		{input: "[]github.com/shopspring/decimal.Decimal", typ: "[]decimal.Decimal", importPath: "github.com/shopspring/decimal", imported: `"github.com/shopspring/decimal"`},
+		{input: "*github.com/foo/bar/v2.Baz", typ: "*bar.Baz", importPath: "github.com/foo/bar/v2", imported: `bar "github.com/foo/bar/v2"`},
+		{input: "gopkg.in/yaml.v3.Node", typ: "yaml.Node", importPath: "gopkg.in/yaml.v3", imported: `yaml "gopkg.in/yaml.v3"`},
+		{input: "example.com/go-toml/v2.Tree", typ: "gotoml.Tree", importPath: "example.com/go-toml/v2", imported: `gotoml "example.com/go-toml/v2"`},
+	}
+	for _, c := range cases {
+		typ, importPath := qualifiedType(c.input)
+		require.Equal(t, c.typ, typ, c.input)
+		require.Equal(t, c.importPath, importPath, c.input)
+		if importPath != "" {
+			require.Equal(t, c.imported, formatImport(importPath), c.input)
+		}
+	}
+}
+
+func TestUnknownScalarsAsAny(t *testing.T) {
+	require.Equal(t, "Platform", commonFunc.FormatOutputType(scalar("Platform")))
+	require.False(t, IsAnyScalar("Platform"))
+
+	generator.SetConfig(generator.Config{UnknownScalarsAsAny: true})
+	t.Cleanup(func() { generator.SetConfig(generator.Config{}) })
+
+	require.True(t, IsAnyScalar("Platform"))
+	require.Equal(t, "any", commonFunc.FormatInputType(scalar("Platform")))
+	require.Equal(t, "[]any", commonFunc.FormatOutputType(listOf(scalar("Platform"))))
+
+	// Scalars with a mapping are unaffected.
+	require.Equal(t, "time.Time", commonFunc.FormatOutputType(scalar("DateTime")))
+	require.Equal(t, "*Container", commonFunc.FormatInputType(scalar("ContainerID")))
+	require.Equal(t, "ContainerID", commonFunc.FormatOutputType(scalar("ContainerID")))
+}
+
+func TestGoVersion(t *testing.T) {
+	require.True(t, TargetsGoVersion("1.18"))
+
+	generator.SetConfig(generator.Config{UnknownScalarsAsAny: true, GoVersion: "1.17.3"})
+	t.Cleanup(func() { generator.SetConfig(generator.Config{}) })
+	require.False(t, TargetsGoVersion("1.18"))
+	require.True(t, TargetsGoVersion("go1.16"))
+	require.Equal(t, "[]interface{}", commonFunc.FormatOutputType(listOf(scalar("Platform"))))
+
+	generator.SetConfig(generator.Config{UnknownScalarsAsAny: true, GoVersion: "go1.18"})
+	require.Equal(t, "any", commonFunc.FormatInputType(scalar("Platform")))
+
+	require.True(t, IsValidGoVersion("1.20"))
+	require.False(t, IsValidGoVersion("latest"))
+}
+
+func TestNewFormatTypeFunc(t *testing.T) {
+	require.Equal(t, &FormatTypeFunc{}, NewFormatTypeFunc())
+
+	SetFormatTypeFunc(NewFormatTypeFunc(
+		WithScalar("URI", "*net/url.URL"),
+		WithInitialisms("GPU"),
+		WithNullableListPointers(),
+	))
+	t.Cleanup(func() { SetFormatTypeFunc(nil) })
+
+	require.Equal(t, "*url.URL", commonFunc.FormatOutputType(scalar("URI")))
+	typ, importPath, ok := MappedScalar("URI")
+	require.True(t, ok)
+	require.Equal(t, "*url.URL", typ)
+	require.Equal(t, "net/url", importPath)
+
+	require.Equal(t, "GPUInfo", formatTypeName("gpuInfo"))
+	require.Equal(t, "*[]*string", commonFunc.FormatOutputType(listOf(scalar(introspection.ScalarString))))
+
+	SetFormatTypeFunc(nil)
+	require.Equal(t, "GpuInfo", formatTypeName("gpuInfo"))
+	require.Equal(t, "[]string", commonFunc.FormatOutputType(listOf(scalar(introspection.ScalarString))))
+}
+
+// TestFormatTypeFuncTemplates renders the templates with the FormatTypeFunc
+// set by SetFormatTypeFunc.
+func TestFormatTypeFuncTemplates(t *testing.T) {
+	site := &introspection.Type{Kind: introspection.TypeKindObject, Name: "Site"}
+	site.Fields = []*introspection.Field{
+		{Name: "resolve", TypeRef: scalar("URI"), Args: introspection.InputValues{
+			{Name: "base", TypeRef: nonNull(scalar("URI"))},
+		}},
+		{Name: "mirrors", TypeRef: listOf(nonNull(scalar(introspection.ScalarString))), Args: introspection.InputValues{
+			{Name: "regions", TypeRef: listOf(scalar(introspection.ScalarString))},
+		}},
+	}
+	for _, f := range site.Fields {
+		f.ParentObject = site
+	}
+	link := &introspection.Type{
+		Kind: introspection.TypeKindInputObject,
+		Name: "Link",
+		InputFields: introspection.InputValues{
+			{Name: "url", TypeRef: nonNull(scalar("URI"))},
+			{Name: "tags", TypeRef: listOf(scalar(introspection.ScalarString))},
+		},
+	}
+	generator.SetSchema(&introspection.Schema{Types: introspection.Types{site, link}})
+	SetFormatTypeFunc(NewFormatTypeFunc(
+		WithScalar("URI", "*net/url.URL"),
+		WithNullableListPointers(),
+	))
+	t.Cleanup(func() {
+		generator.SetSchema(nil)
+		SetFormatTypeFunc(nil)
+		ResetImports()
+	})
+
+	render := func(tmpl *template.Template, typ *introspection.Type) string {
+		var b bytes.Buffer
+		require.NoError(t, tmpl.Execute(&b, typ))
+		_, err := parser.ParseFile(token.NewFileSet(), "", "package test\n"+b.String(), 0)
+		require.NoError(t, err)
+		return b.String()
+	}
+	src := render(Object, site)
+	require.Contains(t, src, "func (r *Site) Resolve(ctx context.Context, base *url.URL) (*url.URL, error) {")
+	require.Contains(t, src, "Regions *[]*string `json:\"regions,omitempty\"`")
+	require.Contains(t, src, "func (r *Site) Mirrors(ctx context.Context, opts ...SiteMirrorsOpts) (*[]string, error) {")
+
+	src = render(Input, link)
+	require.Contains(t, src, "URL *url.URL `json:\"url\"`")
+	require.Contains(t, src, "Tags *[]*string `json:\"tags,omitempty\"`")
+}
@@ -2,10 +2,12 @@ package templates
 
 import (
 	"fmt"
+	"go/token"
 	"regexp"
 	"sort"
 	"strings"
 	"text/template"
+	"unicode"
 
 	"github.com/iancoleman/strcase"
 
@@ -14,48 +16,184 @@ import (
 )
 
 var (
-	commonFunc = generator.NewCommonFunctions(&FormatTypeFunc{})
-	funcMap    = template.FuncMap{
+	formatTypeFunc = &FormatTypeFunc{}
+	commonFunc     = generator.NewCommonFunctions(formatTypeFunc)
+	// The functions of commonFunc are wrapped so that the templates use the
+	// one set by SetFormatTypeFunc.
+	funcMap = template.FuncMap{
 		"Comment":                 comment,
+		"CommentWithNote":         commentWithNote,
+		"OptionalNote":            optionalNote,
+		"NullableNote":            nullableNote,
+		"Config":                  generator.GetConfig,
+		"Import":                  addImport,
+		"FormatImport":            formatImport,
+		"FormatArgsComment":       formatArgsComment,
 		"FormatDeprecation":       formatDeprecation,
-		"FormatReturnType":        commonFunc.FormatReturnType,
-		"FormatInputType":         commonFunc.FormatInputType,
-		"FormatOutputType":        commonFunc.FormatOutputType,
+		"FormatReturnType":        formatReturnType,
+		"FormatFieldType":         formatFieldType,
+		"RequiresMultipart":       requiresMultipart,
+		"UploadScalar":            uploadScalar,
+		"DeepCopyFields":          deepCopyFields,
+		"ValidateFields":          validateFields,
+		"IsOneOf":                 isOneOf,
+		"ReturnsPointer":          returnsPointer,
+		"PresenceHelpers":         presenceHelpers,
+		"FormatInputType":         func(r *introspection.TypeRef) string { return commonFunc.FormatInputType(r) },
+		"FormatInputFieldType":    formatInputFieldType,
+		"InputFieldIsPointer":     inputFieldIsPointer,
+		"FormatInputFieldOptType": formatInputFieldOptType,
+		"FormatOutputType":        func(r *introspection.TypeRef) string { return commonFunc.FormatOutputType(r) },
 		"FormatName":              formatName,
+		"FormatTypeName":          formatTypeName,
+		"FormatFieldName":         formatFieldName,
+		"AffixTypeName":           affixTypeName,
+		"FormatObjectName":        formatObjectName,
+		"FormatInterfaceImplName": formatInterfaceImplName,
+		"InterfaceMethod":         interfaceMethod,
+		"EscapeIdentifier":        escapeIdentifier,
 		"FormatEnum":              formatEnum,
+		"FormatEnumValue":         formatEnumValue,
+		"FormatStructTag":         formatStructTag,
 		"SortEnumFields":          sortEnumFields,
 		"FieldOptionsStructName":  fieldOptionsStructName,
 		"FieldFunction":           fieldFunction,
 		"IsEnum":                  isEnum,
-		"GetArrayField":           commonFunc.GetArrayField,
-		"IsListOfObject":          commonFunc.IsListOfObject,
-		"ToLowerCase":             commonFunc.ToLowerCase,
-		"ToUpperCase":             commonFunc.ToUpperCase,
+		"GetArrayField":           func(f *introspection.Field) []*introspection.Field { return commonFunc.GetArrayField(f) },
+		"IsListOfObject":          func(r *introspection.TypeRef) bool { return commonFunc.IsListOfObject(r) },
+		"ToLowerCase":             func(s string) string { return commonFunc.ToLowerCase(s) },
+		"ToUpperCase":             func(s string) string { return commonFunc.ToUpperCase(s) },
 		"FormatArrayField":        formatArrayField,
 		"FormatArrayToSingleType": formatArrayToSingleType,
-		"ConvertID":               commonFunc.ConvertID,
-		"IsSelfChainable":         commonFunc.IsSelfChainable,
+		"FormatArrayType":         formatArrayType,
+		"ConvertID":               func(f introspection.Field) bool { return commonFunc.ConvertID(f) },
+		"IsSelfChainable":         func(t introspection.Type) bool { return commonFunc.IsSelfChainable(t) },
 	}
 )
 
 // comments out a string
 // Example: `hello\nworld` -> `// hello\n// world\n`
 func comment(s string) string {
-	if s == "" {
+	lines := commentLines(s)
+	if len(lines) == 0 {
 		return ""
 	}
 
-	lines := strings.Split(s, "\n")
-
 	for i, l := range lines {
+		if l == "" {
+			lines[i] = "//"
+			continue
+		}
 		lines[i] = "// " + l
 	}
 	return strings.Join(lines, "\n")
 }
 
+// commentWithNote comments out a description followed by a note, in a
+// separate paragraph.
+// Example: `Port.`, `Optional.` -> `// Port.\n//\n// Optional.`
+func commentWithNote(s string, note string) string {
+	if note == "" {
+		return comment(s)
+	}
+	if strings.TrimSpace(s) == "" {
+		return comment(note)
+	}
+	return comment(s + "\n\n" + note)
+}
+
+// optionalNote documents how an optional input of the given Go type is
+// left unset.
+// Example: `*string` -> `Optional; nil means not set.`
+func optionalNote(typ string) string {
+	if isNillable(typ) {
+		return "Optional; nil means not set."
+	}
+	return "Optional; the zero value means not set."
+}
+
+// nullableNote documents how a field returning a nullable value tells
+// null apart. Objects are returned lazily so they aren't concerned.
+// Example: `contents: String` -> `Nullable; null is returned as the zero value.`
+func nullableNote(f introspection.Field) string {
+	if !f.TypeRef.IsOptional() || !(f.TypeRef.IsScalar() || f.TypeRef.IsList()) || commonFunc.ConvertID(f) {
+		return ""
+	}
+	if returnsPointer(f) || isNillable(formatFieldType(f)) {
+		return "Nullable; nil means null."
+	}
+	return "Nullable; null is returned as the zero value."
+}
+
+// commentLines splits a description into lines, trimming the surrounding
+// whitespace of the description and the trailing whitespace of each line.
+func commentLines(s string) []string {
+	s = strings.TrimSpace(sanitizeComment(s))
+	if s == "" {
+		return nil
+	}
+
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimRightFunc(l, unicode.IsSpace)
+	}
+	return lines
+}
+
+// newlineReplacer normalizes the line terminators of descriptions.
+var newlineReplacer = strings.NewReplacer("\r\n", "\n", "\r", "\n", "\u2028", "\n", "\u2029", "\n")
+
+// sanitizeComment makes a description safe to emit in line comments:
+// line terminators are normalized so that every line gets commented out,
+// and characters Go source can't contain (invalid UTF-8, NUL, BOM) or
+// that would be invisible (control characters) are removed.
+// Since comments are never emitted as blocks, `*/` is left as-is.
+func sanitizeComment(s string) string {
+	s = newlineReplacer.Replace(strings.ToValidUTF8(s, "\uFFFD"))
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\n' || r == '\t':
+			return r
+		case r == '\uFEFF' || unicode.IsControl(r):
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// formatArgsComment documents the required arguments of a field, since
+// unlike the optional ones they don't have an options struct field.
+// Example: `address: String!` -> `// Parameters:\n//   - address: Image's address.`
+func formatArgsComment(args introspection.InputValues) string {
+	var lines []string
+	for _, arg := range args {
+		if arg.TypeRef.IsOptional() {
+			continue
+		}
+		for i, l := range commentLines(arg.Description) {
+			switch {
+			case i == 0:
+				lines = append(lines, "//   - "+escapeIdentifier(arg.Name)+": "+l)
+			case l != "":
+				lines = append(lines, "//     "+l)
+			}
+		}
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return "// Parameters:\n" + strings.Join(lines, "\n")
+}
+
+// defaultDeprecationReason is used for deprecated fields without a reason.
+const defaultDeprecationReason = "no longer supported"
+
 // format the deprecation reason
 // Example: `Replaced by @foo.` -> `// Replaced by Foo\n`
 func formatDeprecation(s string) string {
+	if strings.TrimSpace(s) == "" {
+		s = defaultDeprecationReason
+	}
 	r := regexp.MustCompile("`[a-zA-Z0-9_]+`")
 	matches := r.FindAllString(s, -1)
 	for _, match := range matches {
@@ -73,44 +211,483 @@ func isEnum(t introspection.Type) bool {
 		!strings.HasPrefix(t.Name, "__")
 }
 
+// SetFormatTypeFunc sets the FormatTypeFunc used by the templates, or the
+// zero FormatTypeFunc if nil.
+func SetFormatTypeFunc(f *FormatTypeFunc) {
+	if f == nil {
+		f = &FormatTypeFunc{}
+	}
+	formatTypeFunc = f
+	commonFunc = generator.NewCommonFunctions(f)
+}
+
 // formatName formats a GraphQL name (e.g. object, field, arg) into a Go equivalent
-// Example: `fooId` -> `FooID`
+// using the configured NameFormatter.
+// Formatting an already formatted name is a no-op.
+// Example: `fooId` -> `FooID`, `2fa` -> `X2fa`, `foo-bar` -> `FooBar`
 func formatName(s string) string {
 	if s == generator.QueryStructName {
 		return generator.QueryStructClientName
 	}
+	format := generator.GetConfig().NameFormatter
+	if format == nil {
+		format = DefaultNames
+	}
+	return prefixDigit(format(joinWords(s)), "X")
+}
+
+// joinWords camel-cases the words of a name separated by dashes or dots,
+// and trims its leading underscores so the formatted name is exported.
+// Example: `foo-bar.baz` -> `fooBarBaz`, `_foo` -> `foo`
+func joinWords(s string) string {
+	words := strings.FieldsFunc(s, func(r rune) bool {
+		return r == '-' || r == '.'
+	})
+	for i := 1; i < len(words); i++ {
+		words[i] = upperFirst(words[i])
+	}
+	return strings.TrimLeft(strings.Join(words, ""), "_")
+}
+
+// DefaultNames is the default NameFormatter: names are upper-cased and
+// linted like Go names, preserving initialisms.
+// Example: `fooId` -> `FooID`
+func DefaultNames(s string) string {
+	return lintName(upperFirst(s))
+}
+
+// PascalCaseNames is a NameFormatter strictly converting names to
+// PascalCase, without special-casing initialisms.
+// Example: `fooId` -> `FooId`, `url` -> `Url`
+func PascalCaseNames(s string) string {
+	return strcase.ToCamel(s)
+}
+
+// UnderscoreNames is a NameFormatter behaving like DefaultNames but
+// keeping the underscores of the GraphQL names.
+// Example: `foo_id` -> `Foo_ID`
+func UnderscoreNames(s string) string {
+	words := strings.Split(upperFirst(s), "_")
+	for i, w := range words {
+		if i > 0 && isInitialism(strings.ToUpper(w)) {
+			w = strings.ToUpper(w)
+		}
+		words[i] = lintName(w)
+	}
+	return strings.Join(words, "_")
+}
+
+func upperFirst(s string) string {
 	if len(s) > 0 {
 		s = strings.ToUpper(string(s[0])) + s[1:]
 	}
-	return lintName(s)
+	return s
+}
+
+// formatFieldName formats the name of a field, argument or input field
+// and applies the configured FieldNameTransformer.
+// Example: `item: [Item!]!` -> `Items` with a pluralizing transformer
+func formatFieldName(s string, r *introspection.TypeRef) string {
+	name := formatName(s)
+	if transform := generator.GetConfig().FieldNameTransformer; transform != nil {
+		name = transform(name, r.IsList())
+	}
+	return name
+}
+
+// formatTypeName formats the name of a generated Go type, with the
+// configured prefix and suffix.
+// Example: `user` -> `GithubUser`, `Query` -> `GithubClient` with the
+// `Github` prefix
+func formatTypeName(s string) string {
+	return affixTypeName(formatName(s))
+}
+
+// affixTypeName adds the configured prefix and suffix to a type name.
+func affixTypeName(s string) string {
+	config := generator.GetConfig()
+	return config.TypeNamePrefix + s + config.TypeNameSuffix
+}
+
+// escapeIdentifier makes a GraphQL name used as-is as a Go identifier
+// (e.g. arguments, unexported fields) valid: Go keywords are suffixed with
+// an underscore and names starting with a digit are prefixed with one.
+// Example: `type` -> `type_`, `2fa` -> `_2fa`
+func escapeIdentifier(s string) string {
+	if token.IsKeyword(s) {
+		return s + "_"
+	}
+	return prefixDigit(s, "_")
+}
+
+// prefixDigit prefixes a name starting with a digit, which isn't a valid
+// Go identifier.
+func prefixDigit(s string, prefix string) string {
+	if s != "" && unicode.IsDigit(rune(s[0])) {
+		return prefix + s
+	}
+	return s
+}
+
+// formatObjectName formats the name of the struct generated for an object.
+// Interfaces are generated as Go interfaces, backed by an unexported struct.
+// Example: `Container` -> `Container`, `Node` (interface) -> `nodeImpl`
+func formatObjectName(t *introspection.Type) string {
+	if t.Kind == introspection.TypeKindInterface {
+		return formatInterfaceImplName(formatTypeName(t.Name))
+	}
+	return formatTypeName(t.Name)
+}
+
+// formatInterfaceImplName formats the name of the struct implementing a
+// Go interface generated for a GraphQL interface.
+// Example: `Node` -> `nodeImpl`
+func formatInterfaceImplName(s string) string {
+	return commonFunc.ToLowerCase(s) + "Impl"
 }
 
 // formatName formats a GraphQL Enum value into a Go equivalent
 // Example: `fooId` -> `FooID`
 func formatEnum(s string) string {
 	s = strings.ToLower(s)
-	return strcase.ToCamel(s)
+	return prefixDigit(strcase.ToCamel(s), "X")
+}
+
+// formatEnumValue formats the name of the Go constant of an enum value.
+// The name is prefixed by the enum name if it would otherwise collide
+// with a value of the same or another enum, or another generated
+// identifier (e.g. a type, `ParseX`). The values still colliding once
+// prefixed, like `FOO_BAR` and `foo_bar`, are numbered in schema order.
+// Like type names, it has the configured prefix and suffix.
+// Example: `SHARED` -> `Shared`, or `CacheSharingModeShared` on collision
+func formatEnumValue(t *introspection.Type, v introspection.EnumValue) string {
+	return enumValueNames(t)[v.Name]
+}
+
+// enumValueNames returns the names of the Go constants of the values of an
+// enum, keyed by value (see formatEnumValue).
+func enumValueNames(t *introspection.Type) map[string]string {
+	reserved := generatedNames()
+	others := map[string]bool{}
+	for _, other := range generator.GetSchema().Types {
+		if other.Name == t.Name || !isEnum(*other) {
+			continue
+		}
+		for _, v := range other.EnumValues {
+			others[formatEnum(v.Name)] = true
+		}
+	}
+	own := map[string]int{}
+	for _, v := range t.EnumValues {
+		own[formatEnum(v.Name)]++
+	}
+
+	names := make(map[string]string, len(t.EnumValues))
+	taken := map[string]bool{}
+	for _, v := range t.EnumValues {
+		name := formatEnum(v.Name)
+		if others[name] || own[name] > 1 || reserved[affixTypeName(name)] {
+			name = formatName(t.Name) + name
+		}
+		constant := affixTypeName(name)
+		for i := 2; taken[constant] || reserved[constant]; i++ {
+			constant = affixTypeName(fmt.Sprintf("%s%d", name, i))
+		}
+		taken[constant] = true
+		names[v.Name] = constant
+	}
+	return names
 }
 
-func sortEnumFields(s []introspection.EnumValue) []introspection.EnumValue {
+// generatedNames returns the exported Go identifiers declared for the types
+// of the schema, other than the enum values.
+func generatedNames() map[string]bool {
+	names := map[string]bool{}
+	for _, t := range generator.GetSchema().Types {
+		if strings.HasPrefix(t.Name, "__") {
+			continue
+		}
+		name := formatTypeName(t.Name)
+		names[name] = true
+		switch t.Kind {
+		case introspection.TypeKindEnum:
+			names["All"+name] = true
+			names["Parse"+name] = true
+		case introspection.TypeKindInputObject:
+			names["New"+name] = true
+			names[name+"Opt"] = true
+			for _, f := range t.InputFields {
+				field := formatFieldName(f.Name, f.TypeRef)
+				names["New"+name+"With"+field] = true
+				names[name+"With"+field] = true
+			}
+		case introspection.TypeKindObject, introspection.TypeKindInterface:
+			for _, f := range t.Fields {
+				if f.ParentObject != nil && f.Args.HasOptionals() {
+					names[fieldOptionsStructName(*f)] = true
+				}
+			}
+		}
+	}
+	return names
+}
+
+func sortEnumFields(values []introspection.EnumValue) []introspection.EnumValue {
+	// Sort a copy, the schema order is kept for the All slice.
+	s := append([]introspection.EnumValue{}, values...)
 	sort.SliceStable(s, func(i, j int) bool {
 		return s[i].Name < s[j].Name
 	})
 	return s
 }
 
+// formatStructTag formats the tag of a generated struct field, keyed by the
+// original GraphQL name for each of the configured formats. Nullable fields
+// are omitted when empty, and files to upload are tagged for the
+// querybuilder to send them along the multipart request.
+// Example: `name: String` -> "`json:\"name,omitempty\"`"
+func formatStructTag(name string, r *introspection.TypeRef) string {
+	tag := name
+	if r.IsOptional() {
+		tag += ",omitempty"
+	}
+	formats := generator.GetConfig().StructTags
+	if len(formats) == 0 {
+		formats = []string{"json"}
+	}
+	keys := make([]string, 0, len(formats))
+	for _, format := range formats {
+		keys = append(keys, fmt.Sprintf("%s:%q", format, tag))
+	}
+	if upload := uploadScalar(r); upload != "" {
+		keys = append(keys, fmt.Sprintf("graphql:%q", "upload="+upload))
+	}
+	return "`" + strings.Join(keys, " ") + "`"
+}
+
+// StructTagFormats are the supported formats of the struct tags, see
+// generator.Config.StructTags.
+var StructTagFormats = []string{"json", "yaml", "bson"}
+
+// IsValidStructTag returns true if a struct tag format is supported.
+func IsValidStructTag(format string) bool {
+	for _, f := range StructTagFormats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
 func formatArrayField(fields []*introspection.Field) string {
 	result := []string{}
 
 	for _, f := range fields {
-		result = append(result, fmt.Sprintf("%s: &fields[i].%s", f.Name, commonFunc.ToUpperCase(f.Name)))
+		result = append(result, fmt.Sprintf("%s: &fields[i].%s", escapeIdentifier(f.Name), commonFunc.ToUpperCase(f.Name)))
 	}
 
 	return strings.Join(result, ", ")
 }
 
+// formatArrayToSingleType formats the composite literal type of the
+// elements of a list.
+// Example: `[]Container` -> `Container`, `*[]*Container` -> `&Container`
 func formatArrayToSingleType(arrType string) string {
-	return arrType[2:]
+	elem := formatArrayType(arrType)[2:]
+	if strings.HasPrefix(elem, "*") {
+		elem = "&" + elem[1:]
+	}
+	return elem
+}
+
+// formatArrayType formats the slice type of a list, which is a pointer
+// to the slice for nullable lists with NullableListPointers.
+// Example: `*[]Container` -> `[]Container`
+func formatArrayType(arrType string) string {
+	return strings.TrimPrefix(arrType, "*")
+}
+
+// formatInputFieldType formats the Go type of an input object field.
+// Nullable fields are pointers (see FormatTypeFunc.FormatNullable), and so
+// are recursive fields, see isRecursiveInputField.
+// Example: `name: String` -> `*string`, `name: String!` -> `string`
+func formatInputFieldType(f introspection.InputValue) string {
+	if typ, importPath, ok := OverriddenFieldType(f.ParentObject, f.Name); ok {
+		addImport(importPath)
+		return typ
+	}
+	typ := commonFunc.FormatInputFieldType(f.TypeRef)
+	if isRecursiveInputField(f) && !isNillable(typ) {
+		typ = "*" + typ
+	}
+	return typ
+}
+
+// isRecursiveInputField returns true if a required input object field
+// holds, directly or through other required fields, a value of its parent
+// input object, which would make the generated struct infinitely sized.
+// Lists and nullable fields are references already.
+// Example: `not: Filter!` in `Filter`
+func isRecursiveInputField(f introspection.InputValue) bool {
+	schema := generator.GetSchema()
+	if f.ParentObject == nil || schema == nil {
+		return false
+	}
+	seen := map[string]bool{}
+	var holdsParent func(name string) bool
+	holdsParent = func(name string) bool {
+		if name == f.ParentObject.Name {
+			return true
+		}
+		if seen[name] {
+			return false
+		}
+		seen[name] = true
+		t := schema.Types.Get(name)
+		if t == nil {
+			return false
+		}
+		for _, field := range t.InputFields {
+			if held, ok := heldInputObject(field); ok && holdsParent(held) {
+				return true
+			}
+		}
+		return false
+	}
+	held, ok := heldInputObject(f)
+	return ok && holdsParent(held)
+}
+
+// heldInputObject returns the name of the generated input object a required
+// input object field holds by value, if any.
+func heldInputObject(f introspection.InputValue) (string, bool) {
+	ref := f.TypeRef
+	if ref.Kind != introspection.TypeKindNonNull || ref.OfType.Kind != introspection.TypeKindInputObject {
+		return "", false
+	}
+	if _, _, ok := OverriddenType(ref.OfType.Name); ok {
+		return "", false
+	}
+	return ref.OfType.Name, true
+}
+
+// inputFieldIsPointer returns true if an input object field is a pointer
+// to a value, rather than to an object passed in place of its ID or a
+// scalar mapped to a pointer (e.g. `*math/big.Rat`), which must not be
+// copied.
+func inputFieldIsPointer(f introspection.InputValue) bool {
+	if !strings.HasPrefix(formatInputFieldType(f), "*") {
+		return false
+	}
+	ref := f.TypeRef
+	if ref.Kind == introspection.TypeKindNonNull {
+		ref = ref.OfType
+	}
+	if _, ok := generator.CustomScalar[ref.Name]; ok {
+		if _, _, mapped := MappedScalar(ref.Name); !mapped {
+			return false
+		}
+	}
+	if ref.Kind == introspection.TypeKindScalar {
+		required := f
+		required.TypeRef = &introspection.TypeRef{Kind: introspection.TypeKindNonNull, OfType: ref}
+		return !strings.HasPrefix(formatInputFieldType(required), "*")
+	}
+	return true
+}
+
+// formatInputFieldOptType formats the type of the value setting an optional
+// input object field, which is dereferenced for pointers to values so
+// callers don't need to take the address of temporaries.
+// Example: `name: String` -> `string`
+func formatInputFieldOptType(f introspection.InputValue) string {
+	typ := formatInputFieldType(f)
+	if inputFieldIsPointer(f) {
+		typ = strings.TrimPrefix(typ, "*")
+	}
+	return typ
+}
+
+// returnsPointer returns true if a field returning a nullable scalar is
+// generated with a pointer return type, i.e. a pointer to the value stored
+// on its object (see FormatTypeFunc.FormatNullable).
+func returnsPointer(f introspection.Field) bool {
+	return f.TypeRef.IsOptional() && formatReturnType(f) == "*"+formatFieldType(f)
+}
+
+// overriddenFieldType returns the Go type configured in FieldTypeOverrides
+// for a field returning a scalar.
+func overriddenFieldType(f introspection.Field) (typ string, importPath string, ok bool) {
+	if !f.TypeRef.IsScalar() || commonFunc.ConvertID(f) {
+		return "", "", false
+	}
+	return OverriddenFieldType(f.ParentObject, f.Name)
+}
+
+// formatFieldType formats the Go type of the value of a field, as it's
+// stored on its object.
+// Example: `contents: String` -> `string`
+func formatFieldType(f introspection.Field) string {
+	if typ, importPath, ok := overriddenFieldType(f); ok {
+		addImport(importPath)
+		return typ
+	}
+	return commonFunc.FormatOutputType(f.TypeRef)
+}
+
+// formatReturnType formats the Go type returned by a field.
+// Example: `contents: String` -> `string`, or `*string` with NullableOutputPointers
+func formatReturnType(f introspection.Field) string {
+	if typ, importPath, ok := overriddenFieldType(f); ok {
+		addImport(importPath)
+		return typ
+	}
+	return commonFunc.FormatReturnType(f)
+}
+
+// uploadScalar returns the upload scalar of a type if it's a file to
+// upload, or a list of them.
+func uploadScalar(r *introspection.TypeRef) string {
+	for ; r.OfType != nil; r = r.OfType {
+	}
+	if r.Kind != introspection.TypeKindScalar || !IsUploadScalar(r.Name) {
+		return ""
+	}
+	return r.Name
+}
+
+// requiresMultipart returns true if a field takes files to upload, directly
+// or within input objects, so that it must be sent as a multipart request.
+func requiresMultipart(f introspection.Field) bool {
+	seen := map[string]bool{}
+	var uploads func(r *introspection.TypeRef) bool
+	uploads = func(r *introspection.TypeRef) bool {
+		if uploadScalar(r) != "" {
+			return true
+		}
+		for ; r.OfType != nil; r = r.OfType {
+		}
+		if r.Kind != introspection.TypeKindInputObject || seen[r.Name] {
+			return false
+		}
+		seen[r.Name] = true
+		t := generator.GetSchema().Types.Get(r.Name)
+		if t == nil {
+			return false
+		}
+		for _, field := range t.InputFields {
+			if uploads(field.TypeRef) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, arg := range f.Args {
+		if uploads(arg.TypeRef) {
+			return true
+		}
+	}
+	return false
 }
 
 // fieldOptionsStructName returns the options struct name for a given field
@@ -121,21 +698,42 @@ func fieldOptionsStructName(f introspection.Field) string {
 	// The structure name will not clash with others since everybody else
 	// is prefixed by object name.
 	if f.ParentObject.Name == generator.QueryStructName {
-		return formatName(f.Name) + "Opts"
+		return affixTypeName(formatName(f.Name) + "Opts")
 	}
-	return formatName(f.ParentObject.Name) + formatName(f.Name) + "Opts"
+	return affixTypeName(formatName(f.ParentObject.Name) + formatName(f.Name) + "Opts")
 }
 
 // fieldFunction converts a field into a function signature
 // Example: `contents: String!` -> `func (r *File) Contents(ctx context.Context) (string, error)`
 func fieldFunction(f introspection.Field) string {
-	structName := formatName(f.ParentObject.Name)
+	structName := formatObjectName(f.ParentObject)
 	signature := fmt.Sprintf(`func (r *%s) %s`,
-		structName, formatName(f.Name))
+		structName, formatFieldName(f.Name, f.TypeRef))
+
+	signature += "(" + strings.Join(fieldArgs(f), ", ") + ")"
+
+	retType := formatReturnType(f)
+	switch {
+	case f.TypeRef.IsScalar() || f.TypeRef.IsList():
+		retType = fmt.Sprintf("(%s, error)", retType)
+	case f.TypeRef.IsInterface():
+		// Go interfaces are returned as-is
+	case f.TypeRef.IsUnion():
+		// The member type has to be queried to be known
+		retType = fmt.Sprintf("(*%s, error)", retType)
+	default:
+		retType = "*" + retType
+	}
+	signature += " " + retType
+
+	return signature
+}
 
-	// Generate arguments
+// fieldArgs formats the arguments of the function of a field.
+// Example: `file(path: String!)` -> `path string`
+func fieldArgs(f introspection.Field) []string {
 	args := []string{}
-	if f.TypeRef.IsScalar() || f.TypeRef.IsList() {
+	if f.TypeRef.IsScalar() || f.TypeRef.IsList() || f.TypeRef.IsUnion() {
 		args = append(args, "ctx context.Context")
 	}
 	for _, arg := range f.Args {
@@ -146,9 +744,9 @@ func fieldFunction(f introspection.Field) string {
 		// FIXME: For top-level queries (e.g. File, Directory) if the field is named `id` then keep it as a
 		// scalar (DirectoryID) rather than an object (*Directory).
 		if f.ParentObject.Name == generator.QueryStructName && arg.Name == "id" {
-			args = append(args, fmt.Sprintf("%s %s", arg.Name, commonFunc.FormatOutputType(arg.TypeRef)))
+			args = append(args, fmt.Sprintf("%s %s", escapeIdentifier(arg.Name), commonFunc.FormatOutputType(arg.TypeRef)))
 		} else {
-			args = append(args, fmt.Sprintf("%s %s", arg.Name, commonFunc.FormatInputType(arg.TypeRef)))
+			args = append(args, fmt.Sprintf("%s %s", escapeIdentifier(arg.Name), commonFunc.FormatInputType(arg.TypeRef)))
 		}
 	}
 	// Options (e.g. DirectoryContentsOptions -> <Object><Field>Options)
@@ -158,15 +756,51 @@ func fieldFunction(f introspection.Field) string {
 			fmt.Sprintf("opts ...%s", fieldOptionsStructName(f)),
 		)
 	}
-	signature += "(" + strings.Join(args, ", ") + ")"
+	return args
+}
 
-	retType := commonFunc.FormatReturnType(f)
-	if f.TypeRef.IsScalar() || f.TypeRef.IsList() {
-		retType = fmt.Sprintf("(%s, error)", retType)
-	} else {
-		retType = "*" + retType
+// presenceHelpers formats the `GetX` and `HasX` methods of a field
+// returning a pointer (see returnsPointer), which call its function.
+// Example: `stdout: String` ->
+//
+//	func (r *Container) GetStdout(ctx context.Context) (string, error)
+//	func (r *Container) HasStdout(ctx context.Context) (bool, error)
+func presenceHelpers(f introspection.Field) string {
+	structName := formatObjectName(f.ParentObject)
+	name := formatFieldName(f.Name, f.TypeRef)
+	typ := formatFieldType(f)
+
+	args := fieldArgs(f)
+	call := make([]string, 0, len(args))
+	for _, arg := range args {
+		arg, typ, _ := strings.Cut(arg, " ")
+		if strings.HasPrefix(typ, "...") {
+			arg += "..."
+		}
+		call = append(call, arg)
 	}
-	signature += " " + retType
+	params, callArgs := strings.Join(args, ", "), strings.Join(call, ", ")
 
-	return signature
+	return fmt.Sprintf(`// Get%[2]s is like %[2]s, returning the zero value if it's null.
+func (r *%[1]s) Get%[2]s(%[3]s) (%[5]s, error) {
+	v, err := r.%[2]s(%[4]s)
+	if err != nil || v == nil {
+		var zero %[5]s
+		return zero, err
+	}
+	return *v, nil
+}
+
+// Has%[2]s is like %[2]s, returning true if it's not null.
+func (r *%[1]s) Has%[2]s(%[3]s) (bool, error) {
+	v, err := r.%[2]s(%[4]s)
+	return v != nil, err
+}`, structName, name, params, callArgs, typ)
+}
+
+// interfaceMethod converts a field into the method of a Go interface
+// Example: `contents: String!` -> `Contents(ctx context.Context) (string, error)`
+func interfaceMethod(f introspection.Field) string {
+	receiver := fmt.Sprintf("func (r *%s) ", formatObjectName(f.ParentObject))
+	return strings.TrimPrefix(fieldFunction(f), receiver)
 }
@@ -0,0 +1,942 @@
+package templates
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+	"unicode"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dagger/dagger/codegen/generator"
+	"github.com/dagger/dagger/codegen/introspection"
+)
+
+func enumType(name string, values ...string) *introspection.Type {
+	t := &introspection.Type{
+		Kind: introspection.TypeKindEnum,
+		Name: name,
+	}
+	for _, v := range values {
+		t.EnumValues = append(t.EnumValues, introspection.EnumValue{Name: v})
+	}
+	return t
+}
+
+// renderEnumConsts renders an enum and returns the names of the constants
+// it declares.
+func renderEnumConsts(t *testing.T, typ *introspection.Type) []string {
+	t.Helper()
+
+	var b bytes.Buffer
+	require.NoError(t, Enum.Execute(&b, typ))
+
+	f, err := parser.ParseFile(token.NewFileSet(), "", "package test\n"+b.String(), 0)
+	require.NoError(t, err)
+
+	var consts []string
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.CONST {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			for _, name := range spec.(*ast.ValueSpec).Names {
+				consts = append(consts, name.Name)
+			}
+		}
+	}
+	return consts
+}
+
+func TestEnumConsts(t *testing.T) {
+	protocol := enumType("NetworkProtocol", "TCP", "UDP")
+	sharing := enumType("CacheSharingMode", "SHARED", "PRIVATE", "LOCKED")
+	visibility := enumType("Visibility", "PRIVATE", "PUBLIC")
+	letterCase := enumType("Case", "FOO_BAR", "foo_bar", "BAZ")
+	mode := enumType("Mode", "PARSE_MODE", "ALL_MODE")
+	generator.SetSchema(&introspection.Schema{
+		Types: introspection.Types{
+			protocol,
+			sharing,
+			visibility,
+			letterCase,
+			mode,
+			{Kind: introspection.TypeKindObject, Name: "Public"},
+		},
+	})
+	t.Cleanup(func() { generator.SetSchema(nil) })
+
+	t.Run("unique values are not prefixed", func(t *testing.T) {
+		require.Equal(t, []string{"Tcp", "Udp"}, renderEnumConsts(t, protocol))
+	})
+
+	t.Run("colliding values are prefixed", func(t *testing.T) {
+		require.Equal(t,
+			[]string{"Locked", "CacheSharingModePrivate", "Shared"},
+			renderEnumConsts(t, sharing),
+		)
+		require.Equal(t,
+			[]string{"VisibilityPrivate", "VisibilityPublic"},
+			renderEnumConsts(t, visibility),
+		)
+	})
+
+	t.Run("values colliding in their enum are numbered", func(t *testing.T) {
+		require.Equal(t,
+			[]string{"Baz", "CaseFooBar", "CaseFooBar2"},
+			renderEnumConsts(t, letterCase),
+		)
+	})
+
+	t.Run("values colliding with generated names are prefixed", func(t *testing.T) {
+		require.Equal(t,
+			[]string{"ModeAllMode", "ModeParseMode"},
+			renderEnumConsts(t, mode),
+		)
+	})
+
+	t.Run("constants are exported and collision-free", func(t *testing.T) {
+		seen := map[string]struct{}{}
+		for _, typ := range []*introspection.Type{protocol, sharing, visibility, letterCase, mode} {
+			for _, name := range renderEnumConsts(t, typ) {
+				require.True(t, unicode.IsUpper([]rune(name)[0]), name)
+				require.NotContains(t, seen, name)
+				seen[name] = struct{}{}
+			}
+		}
+	})
+}
+
+func TestEscapeIdentifier(t *testing.T) {
+	keywords := []string{
+		"break", "case", "chan", "const", "continue", "default", "defer",
+		"else", "fallthrough", "for", "func", "go", "goto", "if", "import",
+		"interface", "map", "package", "range", "return", "select", "struct",
+		"switch", "type", "var",
+	}
+
+	object := &introspection.Type{
+		Kind: introspection.TypeKindObject,
+		Name: "Keywords",
+	}
+	for _, kw := range keywords {
+		object.Fields = append(object.Fields, &introspection.Field{
+			Name:    kw,
+			TypeRef: scalar(introspection.ScalarString),
+			Args: introspection.InputValues{
+				{Name: kw, TypeRef: nonNull(scalar(introspection.ScalarString))},
+			},
+			ParentObject: object,
+		})
+	}
+	generator.SetSchema(&introspection.Schema{Types: introspection.Types{object}})
+	t.Cleanup(func() { generator.SetSchema(nil) })
+
+	var b bytes.Buffer
+	require.NoError(t, Object.Execute(&b, object))
+
+	_, err := parser.ParseFile(token.NewFileSet(), "", "package test\n"+b.String(), 0)
+	require.NoError(t, err)
+
+	for _, kw := range keywords {
+		require.Equal(t, kw+"_", escapeIdentifier(kw))
+	}
+	require.Equal(t, "name", escapeIdentifier("name"))
+}
+
+func TestFormatName(t *testing.T) {
+	generator.SetConfig(generator.Config{Initialisms: []string{"GPU"}})
+	t.Cleanup(func() { generator.SetConfig(generator.Config{}) })
+
+	cases := map[string]string{
+		"url":     "URL",
+		"id":      "ID",
+		"api":     "API",
+		"URL":     "URL",
+		"fooId":   "FooID",
+		"withGpu": "WithGPU",
+		"2fa":     "X2fa",
+		"Query":   generator.QueryStructClientName,
+	}
+	for name, want := range cases {
+		require.Equal(t, want, formatName(name), name)
+	}
+
+	require.Equal(t, "_2fa", escapeIdentifier("2fa"))
+	require.Equal(t, "X2Fa", formatEnum("2FA"))
+}
+
+func TestFormatNameIdempotent(t *testing.T) {
+	formatters := map[string]generator.NameFormatter{
+		"default":     nil,
+		"PascalCase":  PascalCaseNames,
+		"underscores": UnderscoreNames,
+	}
+	cases := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "dashes", in: "foo-bar-id", want: "FooBarID"},
+		{name: "dots", in: "foo.bar", want: "FooBar"},
+		{name: "underscores", in: "foo_bar", want: "FooBar"},
+		{name: "leading underscores", in: "__typename", want: "Typename"},
+		{name: "leading digit", in: "2fa", want: "X2fa"},
+		{name: "leading digit after underscore", in: "_2fa", want: "X2fa"},
+		{name: "PascalCase", in: "FooBar", want: "FooBar"},
+		{name: "initialism", in: "HTTPServer", want: "HTTPServer"},
+		{name: "Query", in: "Query", want: generator.QueryStructClientName},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			require.Equal(t, c.want, formatName(c.in))
+		})
+	}
+
+	for fname, format := range formatters {
+		format := format
+		t.Run(fname, func(t *testing.T) {
+			generator.SetConfig(generator.Config{NameFormatter: format})
+			t.Cleanup(func() { generator.SetConfig(generator.Config{}) })
+
+			for _, c := range cases {
+				once := formatName(c.in)
+				require.Equal(t, once, formatName(once), c.in)
+			}
+		})
+	}
+}
+
+func TestNameFormatter(t *testing.T) {
+	cases := []struct {
+		name   string
+		format generator.NameFormatter
+		want   map[string]string
+	}{
+		{
+			name:   "default",
+			format: nil,
+			want:   map[string]string{"fooId": "FooID", "foo_id": "FooID", "2fa": "X2fa"},
+		},
+		{
+			name:   "PascalCase",
+			format: PascalCaseNames,
+			want:   map[string]string{"fooId": "FooId", "foo_id": "FooId", "url": "Url", "2fa": "X2Fa"},
+		},
+		{
+			name:   "underscores",
+			format: UnderscoreNames,
+			want:   map[string]string{"fooId": "FooID", "foo_id": "Foo_ID", "foo_bar_baz": "Foo_bar_baz"},
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			generator.SetConfig(generator.Config{NameFormatter: c.format})
+			t.Cleanup(func() { generator.SetConfig(generator.Config{}) })
+
+			require.Equal(t, generator.QueryStructClientName, formatName("Query"))
+			for name, want := range c.want {
+				require.Equal(t, want, formatName(name), name)
+			}
+		})
+	}
+}
+
+func TestComment(t *testing.T) {
+	require.Equal(t, "", comment(""))
+	require.Equal(t, "", comment(" \n\t"))
+	require.Equal(t, "// hello\n// world", comment("hello\nworld"))
+	require.Equal(t,
+		"// Shares the cache volume,\n//\n// but serializes writes",
+		comment("\n  Shares the cache volume, \r\n\r\nbut serializes writes\n\n"),
+	)
+
+	args := introspection.InputValues{
+		{Name: "address", Description: "Image's address.\n\nFormatted as [host]/[repo].", TypeRef: nonNull(scalar(introspection.ScalarString))},
+		{Name: "platform", Description: "Ignored, optional.", TypeRef: scalar(introspection.ScalarString)},
+		{Name: "type", Description: "Keyword.", TypeRef: nonNull(scalar(introspection.ScalarString))},
+	}
+	require.Equal(t,
+		"// Parameters:\n//   - address: Image's address.\n//     Formatted as [host]/[repo].\n//   - type_: Keyword.",
+		formatArgsComment(args),
+	)
+}
+
+func TestCommentSanitize(t *testing.T) {
+	nasty := "Ends a /* block */ early.\rOld Mac line.\u2028Unicode line.\r\n" +
+		"NUL\x00, BOM\ufeff, bell\a and \xffinvalid.\n\tIndented */"
+	require.Equal(t,
+		"// Ends a /* block */ early.\n// Old Mac line.\n// Unicode line.\n"+
+			"// NUL, BOM, bell and \uFFFDinvalid.\n// \tIndented */",
+		comment(nasty),
+	)
+
+	src := "package test\n\n" + comment(nasty) + "\ntype Foo string\n" + formatDeprecation(nasty) + "\nconst Bar = 1\n"
+	f, err := parser.ParseFile(token.NewFileSet(), "", src, parser.ParseComments)
+	require.NoError(t, err)
+	require.Len(t, f.Decls, 2)
+}
+
+func TestFormatDeprecation(t *testing.T) {
+	require.Equal(t, "// Deprecated: Replaced by WithFooID.", formatDeprecation("Replaced by `withFooId`."))
+	require.Equal(t, "// Deprecated: no longer supported", formatDeprecation(""))
+
+	object := &introspection.Type{Kind: introspection.TypeKindObject, Name: "Foo"}
+	object.Fields = []*introspection.Field{{
+		Name:         "bar",
+		Description:  "Returns bar.",
+		TypeRef:      nonNull(scalar(introspection.ScalarString)),
+		IsDeprecated: true,
+		ParentObject: object,
+	}}
+	generator.SetSchema(&introspection.Schema{Types: introspection.Types{object}})
+	t.Cleanup(func() { generator.SetSchema(nil) })
+
+	var b bytes.Buffer
+	require.NoError(t, Object.Execute(&b, object))
+	require.Contains(t, b.String(), "// Returns bar.\n//\n// Deprecated: no longer supported\nfunc (r *Foo) Bar(")
+}
+
+func TestInterface(t *testing.T) {
+	entry := &introspection.Type{
+		Kind: introspection.TypeKindInterface,
+		Name: "Entry",
+	}
+	entry.Fields = []*introspection.Field{
+		{
+			Name:         "name",
+			TypeRef:      nonNull(scalar(introspection.ScalarString)),
+			ParentObject: entry,
+		},
+		{
+			Name:         "parent",
+			TypeRef:      &introspection.TypeRef{Kind: introspection.TypeKindInterface, Name: "Entry"},
+			ParentObject: entry,
+		},
+	}
+	generator.SetSchema(&introspection.Schema{Types: introspection.Types{entry}})
+	t.Cleanup(func() { generator.SetSchema(nil) })
+
+	require.Equal(t, "entryImpl", formatObjectName(entry))
+	require.Equal(t, "Name(ctx context.Context) (string, error)", interfaceMethod(*entry.Fields[0]))
+	require.Equal(t, "Parent() Entry", interfaceMethod(*entry.Fields[1]))
+
+	var b bytes.Buffer
+	require.NoError(t, Interface.Execute(&b, entry))
+	require.NoError(t, Object.Execute(&b, entry))
+	require.Contains(t, b.String(), "return &entryImpl {")
+
+	_, err := parser.ParseFile(token.NewFileSet(), "", "package test\n"+b.String(), 0)
+	require.NoError(t, err)
+}
+
+func TestUnion(t *testing.T) {
+	object := func(name string) *introspection.TypeRef {
+		return &introspection.TypeRef{Kind: introspection.TypeKindObject, Name: name}
+	}
+	result := &introspection.Type{
+		Kind:          introspection.TypeKindUnion,
+		Name:          "SearchResult",
+		PossibleTypes: []*introspection.TypeRef{object("Directory"), object("File")},
+	}
+	query := &introspection.Type{Kind: introspection.TypeKindObject, Name: "Query"}
+	query.Fields = []*introspection.Field{{
+		Name:         "search",
+		TypeRef:      &introspection.TypeRef{Kind: introspection.TypeKindUnion, Name: "SearchResult"},
+		ParentObject: query,
+	}}
+	generator.SetSchema(&introspection.Schema{Types: introspection.Types{result, query}})
+	t.Cleanup(func() { generator.SetSchema(nil) })
+
+	require.Equal(t, "SearchResult", commonFunc.FormatOutputType(query.Fields[0].TypeRef))
+	require.Equal(t, "func (r *Client) Search(ctx context.Context) (*SearchResult, error)", fieldFunction(*query.Fields[0]))
+
+	var b bytes.Buffer
+	require.NoError(t, Union.Execute(&b, result))
+	require.Contains(t, b.String(), "Typename string `json:\"__typename\"`")
+	require.Contains(t, b.String(), "Directory *Directory `json:\"-\"`")
+	require.Contains(t, b.String(), "case \"File\":\n\t\tr.File = &File{}")
+	require.Contains(t, b.String(), "r.File.q = q.InlineFragment(\"File\")")
+
+	require.NoError(t, Object.Execute(&b, query))
+	require.Contains(t, b.String(), "response.bind(q, r.c)")
+
+	_, err := parser.ParseFile(token.NewFileSet(), "", "package test\n"+b.String(), 0)
+	require.NoError(t, err)
+}
+
+func TestEnumDeprecation(t *testing.T) {
+	protocol := enumType("NetworkProtocol", "TCP", "UDP", "SCTP")
+	protocol.EnumValues[0].Description = "Transmission Control Protocol."
+	protocol.EnumValues[0].IsDeprecated = true
+	protocol.EnumValues[0].DeprecationReason = "Use `SCTP`."
+	protocol.EnumValues[1].IsDeprecated = true
+	generator.SetSchema(&introspection.Schema{Types: introspection.Types{protocol}})
+	t.Cleanup(func() { generator.SetSchema(nil) })
+
+	var b bytes.Buffer
+	require.NoError(t, Enum.Execute(&b, protocol))
+	require.Contains(t, b.String(), "// Transmission Control Protocol.\n\t//\n\t// Deprecated: Use SCTP.\n\tTcp NetworkProtocol")
+	require.Contains(t, b.String(), "\n\t// Deprecated: no longer supported\n\tUdp NetworkProtocol")
+	require.Contains(t, b.String(), "\n\tSctp NetworkProtocol")
+}
+
+func TestEnumValuesSlice(t *testing.T) {
+	protocol := enumType("NetworkProtocol", "UDP", "TCP", "SCTP")
+	protocol.EnumValues[1].IsDeprecated = true
+	generator.SetSchema(&introspection.Schema{Types: introspection.Types{protocol}})
+	t.Cleanup(func() {
+		generator.SetSchema(nil)
+		generator.SetConfig(generator.Config{})
+	})
+
+	var b bytes.Buffer
+	require.NoError(t, Enum.Execute(&b, protocol))
+	require.Contains(t, b.String(), "var AllNetworkProtocol = []NetworkProtocol{\n\tUdp,\n\tTcp,\n\tSctp,\n}")
+	// The constants are still sorted.
+	require.Contains(t, b.String(), "case Sctp, Tcp, Udp:")
+
+	_, err := parser.ParseFile(token.NewFileSet(), "", "package test\n"+b.String(), 0)
+	require.NoError(t, err)
+
+	generator.SetConfig(generator.Config{OmitDeprecatedEnumValues: true})
+	b.Reset()
+	require.NoError(t, Enum.Execute(&b, protocol))
+	require.Contains(t, b.String(), "var AllNetworkProtocol = []NetworkProtocol{\n\tUdp,\n\tSctp,\n}")
+	require.Contains(t, b.String(), "\tTcp NetworkProtocol = \"TCP\"")
+}
+
+func TestStrictEnums(t *testing.T) {
+	protocol := enumType("NetworkProtocol", "UDP", "TCP")
+	generator.SetSchema(&introspection.Schema{Types: introspection.Types{protocol}})
+	generator.SetConfig(generator.Config{StrictEnums: true})
+	t.Cleanup(func() {
+		generator.SetSchema(nil)
+		generator.SetConfig(generator.Config{})
+	})
+
+	var b bytes.Buffer
+	require.NoError(t, Enum.Execute(&b, protocol))
+	require.Contains(t, b.String(), "func ParseNetworkProtocol(s string) (NetworkProtocol, error) {")
+	require.Contains(t, b.String(), "func (e *NetworkProtocol) UnmarshalJSON(data []byte) error {")
+
+	_, err := parser.ParseFile(token.NewFileSet(), "", "package test\n"+b.String(), 0)
+	require.NoError(t, err)
+}
+
+func TestEnumParse(t *testing.T) {
+	protocol := enumType("NetworkProtocol", "UDP", "TCP")
+	generator.SetSchema(&introspection.Schema{Types: introspection.Types{protocol}})
+	t.Cleanup(func() { generator.SetSchema(nil) })
+
+	var b bytes.Buffer
+	require.NoError(t, Enum.Execute(&b, protocol))
+	require.Contains(t, b.String(), "func (e NetworkProtocol) String() string {")
+	require.Contains(t, b.String(), "func ParseNetworkProtocol(s string) (NetworkProtocol, error) {")
+	require.Contains(t, b.String(), "case Tcp, Udp:\n\t\treturn v, nil")
+	// Unknown values are kept unless StrictEnums is set.
+	require.NotContains(t, b.String(), "UnmarshalJSON")
+
+	_, err := parser.ParseFile(token.NewFileSet(), "", "package test\n"+b.String(), 0)
+	require.NoError(t, err)
+}
+
+func TestInputObject(t *testing.T) {
+	input := &introspection.Type{
+		Kind: introspection.TypeKindInputObject,
+		Name: "BuildArg",
+		InputFields: introspection.InputValues{
+			{Name: "name", TypeRef: nonNull(scalar(introspection.ScalarString))},
+			{Name: "value", TypeRef: scalar(introspection.ScalarString)},
+			{Name: "tags", TypeRef: listOf(nonNull(scalar(introspection.ScalarString)))},
+			{Name: "secret", TypeRef: scalar("SecretID")},
+			{Name: "parent", TypeRef: &introspection.TypeRef{Kind: introspection.TypeKindInputObject, Name: "BuildArg"}},
+		},
+	}
+	generator.SetSchema(&introspection.Schema{Types: introspection.Types{input}})
+	t.Cleanup(func() { generator.SetSchema(nil) })
+
+	var b bytes.Buffer
+	require.NoError(t, Input.Execute(&b, input))
+	for _, field := range []string{
+		"Name string `json:\"name\"`",
+		"Value *string `json:\"value,omitempty\"`",
+		"Tags []string `json:\"tags,omitempty\"`",
+		"Secret *Secret `json:\"secret,omitempty\"`",
+		"Parent *BuildArg `json:\"parent,omitempty\"`",
+	} {
+		require.Contains(t, b.String(), field)
+	}
+
+	_, err := parser.ParseFile(token.NewFileSet(), "", "package test\n"+b.String(), 0)
+	require.NoError(t, err)
+}
+
+func TestFieldNameTransformer(t *testing.T) {
+	input := &introspection.Type{
+		Kind: introspection.TypeKindInputObject,
+		Name: "Filter",
+		InputFields: introspection.InputValues{
+			{Name: "tag", TypeRef: nonNull(listOf(nonNull(scalar(introspection.ScalarString))))},
+			{Name: "name", TypeRef: scalar(introspection.ScalarString)},
+		},
+	}
+	query := &introspection.Type{Kind: introspection.TypeKindObject, Name: "Query"}
+	query.Fields = []*introspection.Field{{
+		Name:         "item",
+		TypeRef:      nonNull(listOf(nonNull(scalar(introspection.ScalarString)))),
+		ParentObject: query,
+	}}
+	generator.SetSchema(&introspection.Schema{Types: introspection.Types{input, query}})
+	t.Cleanup(func() { generator.SetSchema(nil) })
+
+	require.Equal(t, "func (r *Client) Item(ctx context.Context) ([]string, error)", fieldFunction(*query.Fields[0]))
+
+	generator.SetConfig(generator.Config{FieldNameTransformer: func(name string, list bool) string {
+		if list {
+			return name + "s"
+		}
+		return name
+	}})
+	t.Cleanup(func() { generator.SetConfig(generator.Config{}) })
+
+	require.Equal(t, "func (r *Client) Items(ctx context.Context) ([]string, error)", fieldFunction(*query.Fields[0]))
+
+	var b bytes.Buffer
+	require.NoError(t, Input.Execute(&b, input))
+	require.Contains(t, b.String(), "Tags []string `json:\"tag\"`")
+	require.Contains(t, b.String(), "Name *string `json:\"name,omitempty\"`")
+	require.Contains(t, b.String(), "Tags: tag,")
+
+	_, err := parser.ParseFile(token.NewFileSet(), "", "package test\n"+b.String(), 0)
+	require.NoError(t, err)
+}
+
+func TestInputObjectConstructor(t *testing.T) {
+	input := &introspection.Type{
+		Kind: introspection.TypeKindInputObject,
+		Name: "BuildArg",
+		InputFields: introspection.InputValues{
+			{Name: "name", TypeRef: nonNull(scalar(introspection.ScalarString))},
+			{Name: "value", TypeRef: scalar(introspection.ScalarString)},
+			{Name: "at", TypeRef: scalar("DateTime")},
+			{Name: "secret", TypeRef: scalar("SecretID")},
+			{Name: "tags", TypeRef: listOf(nonNull(scalar(introspection.ScalarString)))},
+			{Name: "amount", TypeRef: scalar("Decimal")},
+		},
+	}
+	price := &introspection.Type{
+		Kind:    introspection.TypeKindInputObject,
+		Name:    "Price",
+		IsOneOf: true,
+		InputFields: introspection.InputValues{
+			{Name: "amount", TypeRef: scalar("Decimal")},
+			{Name: "currency", TypeRef: scalar(introspection.ScalarString)},
+		},
+	}
+	generator.SetSchema(&introspection.Schema{Types: introspection.Types{input, price}})
+	t.Cleanup(func() { generator.SetSchema(nil) })
+
+	var b bytes.Buffer
+	require.NoError(t, Input.Execute(&b, input))
+	require.NoError(t, Input.Execute(&b, price))
+	for _, s := range []string{
+		"type BuildArgOpt func(r *BuildArg)",
+		"func BuildArgWithValue(value string) BuildArgOpt {",
+		"r.Value = &value",
+		"func BuildArgWithAt(at time.Time) BuildArgOpt {",
+		"r.At = &at",
+		"func BuildArgWithSecret(secret *Secret) BuildArgOpt {",
+		"r.Secret = secret",
+		"func BuildArgWithTags(tags []string) BuildArgOpt {",
+		// pointers to big.Rat values, which can't be copied, are kept
+		"func BuildArgWithAmount(amount *big.Rat) BuildArgOpt {",
+		"r.Amount = amount",
+		"func NewBuildArg(name string, opts ...BuildArgOpt) BuildArg {",
+		"func NewPriceWithAmount(amount *big.Rat) Price {",
+		"return Price{Amount: amount}",
+		"func NewPriceWithCurrency(currency string) Price {",
+		"return Price{Currency: &currency}",
+	} {
+		require.Contains(t, b.String(), s)
+	}
+
+	_, err := parser.ParseFile(token.NewFileSet(), "", "package test\n"+b.String(), 0)
+	require.NoError(t, err)
+}
+
+func TestPresenceHelpers(t *testing.T) {
+	input := &introspection.Type{
+		Kind: introspection.TypeKindInputObject,
+		Name: "BuildArg",
+		InputFields: introspection.InputValues{
+			{Name: "name", TypeRef: nonNull(scalar(introspection.ScalarString))},
+			{Name: "value", TypeRef: scalar(introspection.ScalarString)},
+			{Name: "tags", TypeRef: listOf(nonNull(scalar(introspection.ScalarString)))},
+			{Name: "amount", TypeRef: scalar("Decimal")},
+		},
+	}
+	generator.SetSchema(&introspection.Schema{Types: introspection.Types{input}})
+	t.Cleanup(func() {
+		generator.SetSchema(nil)
+		generator.SetConfig(generator.Config{})
+	})
+
+	var b bytes.Buffer
+	require.NoError(t, Input.Execute(&b, input))
+	require.NotContains(t, b.String(), "GetValue")
+
+	generator.SetConfig(generator.Config{PresenceHelpers: true})
+	b.Reset()
+	require.NoError(t, Input.Execute(&b, input))
+	require.Contains(t, b.String(), "func (r *BuildArg) GetValue() string {")
+	require.Contains(t, b.String(), "func (r *BuildArg) HasValue() bool {")
+	require.NotContains(t, b.String(), "GetName")
+	require.NotContains(t, b.String(), "GetTags")
+	require.NotContains(t, b.String(), "GetAmount")
+
+	_, err := parser.ParseFile(token.NewFileSet(), "", "package test\n"+b.String(), 0)
+	require.NoError(t, err)
+}
+
+func TestOutputPresenceHelpers(t *testing.T) {
+	container := &introspection.Type{Kind: introspection.TypeKindObject, Name: "Container"}
+	container.Fields = []*introspection.Field{
+		{Name: "stdout", TypeRef: nonNull(scalar(introspection.ScalarString))},
+		{Name: "envVariable", TypeRef: scalar(introspection.ScalarString), Args: introspection.InputValues{
+			{Name: "name", TypeRef: nonNull(scalar(introspection.ScalarString))},
+			{Name: "expand", TypeRef: scalar(introspection.ScalarBoolean)},
+		}},
+	}
+	for _, f := range container.Fields {
+		f.ParentObject = container
+	}
+	generator.SetSchema(&introspection.Schema{Types: introspection.Types{container}})
+	t.Cleanup(func() {
+		generator.SetSchema(nil)
+		generator.SetConfig(generator.Config{})
+	})
+
+	render := func() string {
+		var b bytes.Buffer
+		require.NoError(t, Object.Execute(&b, container))
+		_, err := parser.ParseFile(token.NewFileSet(), "", "package test\n"+b.String(), 0)
+		require.NoError(t, err)
+		return b.String()
+	}
+
+	// The fields only return pointers with NullableOutputPointers.
+	generator.SetConfig(generator.Config{PresenceHelpers: true})
+	require.NotContains(t, render(), "GetEnvVariable")
+
+	generator.SetConfig(generator.Config{PresenceHelpers: true, NullableOutputPointers: true})
+	src := render()
+	require.Contains(t, src, `func (r *Container) GetEnvVariable(ctx context.Context, name string, opts ...ContainerEnvVariableOpts) (string, error) {
+	v, err := r.EnvVariable(ctx, name, opts...)
+	if err != nil || v == nil {
+		var zero string
+		return zero, err
+	}
+	return *v, nil
+}`)
+	require.Contains(t, src, `func (r *Container) HasEnvVariable(ctx context.Context, name string, opts ...ContainerEnvVariableOpts) (bool, error) {
+	v, err := r.EnvVariable(ctx, name, opts...)
+	return v != nil, err
+}`)
+	require.NotContains(t, src, "GetStdout")
+}
+
+func TestTypeNameAffixes(t *testing.T) {
+	protocol := enumType("NetworkProtocol", "UDP", "TCP")
+	platform := &introspection.Type{Kind: introspection.TypeKindScalar, Name: "Platform"}
+	generator.SetSchema(&introspection.Schema{Types: introspection.Types{protocol, platform}})
+	generator.SetConfig(generator.Config{TypeNamePrefix: "Gh", TypeNameSuffix: "V1"})
+	t.Cleanup(func() {
+		generator.SetSchema(nil)
+		generator.SetConfig(generator.Config{})
+	})
+
+	object := &introspection.TypeRef{Kind: introspection.TypeKindObject, Name: "Container"}
+	require.Equal(t, "*GhContainerV1", commonFunc.FormatInputType(scalar("ContainerID")))
+	require.Equal(t, "GhContainerIDV1", commonFunc.FormatOutputType(scalar("ContainerID")))
+	require.Equal(t, "GhContainerV1", commonFunc.FormatOutputType(object))
+	require.Equal(t, "GhNetworkProtocolV1", commonFunc.FormatOutputType(&introspection.TypeRef{Kind: introspection.TypeKindEnum, Name: "NetworkProtocol"}))
+	require.Equal(t, "GhIDV1", commonFunc.FormatOutputType(scalar(introspection.ScalarID)))
+	require.True(t, GeneratesIDType())
+	require.Equal(t, "GhClientV1", formatTypeName(generator.QueryStructName))
+
+	var b bytes.Buffer
+	require.NoError(t, Enum.Execute(&b, protocol))
+	require.Contains(t, b.String(), "GhTcpV1 GhNetworkProtocolV1 = \"TCP\"")
+	require.Contains(t, b.String(), "func ParseGhNetworkProtocolV1(s string) (GhNetworkProtocolV1, error) {")
+
+	b.Reset()
+	require.NoError(t, Scalar.Execute(&b, platform))
+	require.Contains(t, b.String(), "type GhPlatformV1 string")
+	require.Contains(t, b.String(), "func (s GhPlatformV1) XXX_GraphQLType() string {\n\treturn \"Platform\"\n}")
+
+	_, err := parser.ParseFile(token.NewFileSet(), "", "package test\n"+b.String(), 0)
+	require.NoError(t, err)
+}
+
+func TestNullabilityNotes(t *testing.T) {
+	require.Equal(t, "// Optional; nil means not set.", commentWithNote("", optionalNote("*string")))
+	require.Equal(t, "// Target stage.\n//\n// Optional; the zero value means not set.", commentWithNote("Target stage.", optionalNote("string")))
+	require.Equal(t, "// Target stage.", commentWithNote("Target stage.", ""))
+
+	object := &introspection.TypeRef{Kind: introspection.TypeKindObject, Name: "Container"}
+	require.Equal(t, "", nullableNote(introspection.Field{Name: "stdout", TypeRef: nonNull(scalar("String"))}))
+	require.Equal(t, "", nullableNote(introspection.Field{Name: "parent", TypeRef: object}))
+	require.Equal(t, "Nullable; null is returned as the zero value.", nullableNote(introspection.Field{Name: "envVariable", TypeRef: scalar("String")}))
+	require.Equal(t, "Nullable; nil means null.", nullableNote(introspection.Field{Name: "entrypoint", TypeRef: listOf(nonNull(scalar("String")))}))
+
+	// Null lists of objects are returned as nil too.
+	command := &introspection.Type{Kind: introspection.TypeKindObject, Name: "Command"}
+	command.Fields = []*introspection.Field{
+		{Name: "name", TypeRef: nonNull(scalar(introspection.ScalarString))},
+	}
+	project := &introspection.Type{Kind: introspection.TypeKindObject, Name: "Project"}
+	project.Fields = []*introspection.Field{
+		{Name: "commands", TypeRef: listOf(nonNull(&introspection.TypeRef{Kind: introspection.TypeKindObject, Name: "Command"}))},
+	}
+	schema := &introspection.Schema{Types: introspection.Types{command, project}}
+	generator.SetSchemaParents(schema)
+	generator.SetSchema(schema)
+	t.Cleanup(func() { generator.SetSchema(nil) })
+	require.Equal(t, "Nullable; nil means null.", nullableNote(*project.Fields[0]))
+
+	var b bytes.Buffer
+	require.NoError(t, Object.Execute(&b, project))
+	require.Contains(t, b.String(), "if fields == nil {\n            return nil\n        }")
+}
+
+func TestFieldTypeOverrides(t *testing.T) {
+	generator.SetConfig(generator.Config{
+		NullableOutputPointers: true,
+		FieldTypeOverrides: map[string]string{
+			"Container.endpoint":  "*net/netip.AddrPort",
+			"Container.withLabel": "Tag",
+			"BuildArg.value":      "encoding/json.Number",
+		},
+	})
+	ResetImports()
+	t.Cleanup(func() {
+		generator.SetConfig(generator.Config{})
+		ResetImports()
+	})
+
+	container := &introspection.Type{Kind: introspection.TypeKindObject, Name: "Container"}
+	endpoint := introspection.Field{Name: "endpoint", TypeRef: scalar("String"), ParentObject: container}
+	hostname := introspection.Field{Name: "hostname", TypeRef: scalar("String"), ParentObject: container}
+	require.Equal(t, "*netip.AddrPort", formatReturnType(endpoint))
+	require.Equal(t, "*netip.AddrPort", formatFieldType(endpoint))
+	require.False(t, returnsPointer(endpoint))
+	require.Equal(t, "*string", formatReturnType(hostname))
+	require.Equal(t, []string{"net/netip"}, Imports())
+
+	// Only fields returning scalars are overridden.
+	withLabel := introspection.Field{
+		Name:         "withLabel",
+		TypeRef:      nonNull(&introspection.TypeRef{Kind: introspection.TypeKindObject, Name: "Container"}),
+		ParentObject: container,
+	}
+	require.Equal(t, "Container", formatFieldType(withLabel))
+
+	buildArg := &introspection.Type{Kind: introspection.TypeKindInputObject, Name: "BuildArg"}
+	value := introspection.InputValue{Name: "value", TypeRef: scalar("String"), ParentObject: buildArg}
+	require.Equal(t, "json.Number", formatInputFieldType(value))
+	value.ParentObject = nil
+	require.Equal(t, "*string", formatInputFieldType(value))
+}
+
+func TestRequiresMultipart(t *testing.T) {
+	attachment := &introspection.Type{
+		Kind: introspection.TypeKindInputObject,
+		Name: "Attachment",
+		InputFields: introspection.InputValues{
+			{Name: "file", TypeRef: scalar("Upload")},
+		},
+	}
+	generator.SetSchema(&introspection.Schema{Types: introspection.Types{attachment}})
+	t.Cleanup(func() { generator.SetSchema(nil) })
+
+	input := &introspection.TypeRef{Kind: introspection.TypeKindInputObject, Name: "Attachment"}
+	require.True(t, requiresMultipart(introspection.Field{Args: introspection.InputValues{
+		{Name: "file", TypeRef: nonNull(scalar("Upload"))},
+	}}))
+	require.True(t, requiresMultipart(introspection.Field{Args: introspection.InputValues{
+		{Name: "attachments", TypeRef: listOf(nonNull(input))},
+	}}))
+	require.False(t, requiresMultipart(introspection.Field{Args: introspection.InputValues{
+		{Name: "name", TypeRef: scalar("String")},
+	}}))
+
+	// Only the Upload values are sent as files.
+	require.Equal(t, "Upload", uploadScalar(listOf(nonNull(scalar("Upload")))))
+	require.Empty(t, uploadScalar(nonNull(input)))
+	require.Equal(t, "`json:\"file,omitempty\" graphql:\"upload=Upload\"`", formatStructTag("file", scalar("Upload")))
+	require.Equal(t, "`json:\"name\"`", formatStructTag("name", nonNull(scalar("String"))))
+
+	directory := &introspection.Type{Kind: introspection.TypeKindObject, Name: "Directory"}
+	directory.Fields = []*introspection.Field{
+		{Name: "withFile", TypeRef: nonNull(&introspection.TypeRef{Kind: introspection.TypeKindObject, Name: "Directory"}), Args: introspection.InputValues{
+			{Name: "file", TypeRef: nonNull(scalar("Upload"))},
+			{Name: "name", TypeRef: nonNull(scalar("String"))},
+			{Name: "attachments", TypeRef: listOf(nonNull(input))},
+		}},
+	}
+	directory.Fields[0].ParentObject = directory
+	generator.SetSchema(&introspection.Schema{Types: introspection.Types{attachment, directory}})
+	var b bytes.Buffer
+	require.NoError(t, Object.Execute(&b, directory))
+	src := b.String()
+	require.Contains(t, src, "q = q.Multipart()")
+	require.Contains(t, src, `q = q.UploadArg("file", "Upload", file)`)
+	require.Contains(t, src, `q = q.Arg("name", name)`)
+	require.Contains(t, src, `q = q.Arg("attachments", opts[i].Attachments)`)
+}
+
+func TestRecursiveInputObject(t *testing.T) {
+	input := func(name string) *introspection.TypeRef {
+		return &introspection.TypeRef{Kind: introspection.TypeKindInputObject, Name: name}
+	}
+	filter := &introspection.Type{
+		Kind: introspection.TypeKindInputObject,
+		Name: "Filter",
+		InputFields: introspection.InputValues{
+			{Name: "not", TypeRef: nonNull(input("Filter"))},
+			{Name: "and", TypeRef: listOf(nonNull(input("Filter")))},
+			{Name: "group", TypeRef: nonNull(input("Group"))},
+			{Name: "range", TypeRef: nonNull(input("Range"))},
+		},
+	}
+	group := &introspection.Type{
+		Kind: introspection.TypeKindInputObject,
+		Name: "Group",
+		InputFields: introspection.InputValues{
+			{Name: "filter", TypeRef: nonNull(input("Filter"))},
+		},
+	}
+	rng := &introspection.Type{
+		Kind: introspection.TypeKindInputObject,
+		Name: "Range",
+		InputFields: introspection.InputValues{
+			{Name: "from", TypeRef: nonNull(scalar(introspection.ScalarString))},
+		},
+	}
+	optional := &introspection.Type{
+		Kind: introspection.TypeKindInputObject,
+		Name: "Optional",
+		InputFields: introspection.InputValues{
+			{Name: "next", TypeRef: input("Optional")},
+		},
+	}
+	schema := &introspection.Schema{Types: introspection.Types{filter, group, rng, optional}}
+	generator.SetSchemaParents(schema)
+	generator.SetSchema(schema)
+	// The presence helpers are only generated for the nullable fields,
+	// not the required ones stored as pointers.
+	generator.SetConfig(generator.Config{PresenceHelpers: true})
+	t.Cleanup(func() {
+		generator.SetSchema(nil)
+		generator.SetConfig(generator.Config{})
+	})
+
+	var b bytes.Buffer
+	for _, input := range schema.Types {
+		require.NoError(t, Input.Execute(&b, input))
+	}
+	for _, field := range []string{
+		// Directly recursive.
+		"Not *Filter `json:\"not\"`",
+		"And []Filter `json:\"and,omitempty\"`",
+		// Indirectly recursive.
+		"Group *Group `json:\"group\"`",
+		"Filter *Filter `json:\"filter\"`",
+		"Range Range `json:\"range\"`",
+		"Next *Optional `json:\"next,omitempty\"`",
+		"func (r *Optional) HasNext() bool {",
+	} {
+		require.Contains(t, b.String(), field)
+	}
+	require.NotContains(t, b.String(), "HasNot")
+	require.NotContains(t, b.String(), "GetGroup")
+	require.NotContains(t, b.String(), "HasFilter")
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", "package test\n"+b.String(), 0)
+	require.NoError(t, err)
+	_, err = (&types.Config{}).Check("test", fset, []*ast.File{f}, nil)
+	require.NoError(t, err)
+}
+
+func TestStructTags(t *testing.T) {
+	require.Equal(t, "`json:\"name\"`", formatStructTag("name", nonNull(scalar(introspection.ScalarString))))
+
+	generator.SetConfig(generator.Config{StructTags: []string{"json", "yaml", "bson"}})
+	t.Cleanup(func() { generator.SetConfig(generator.Config{}) })
+	require.Equal(t, "`json:\"name\" yaml:\"name\" bson:\"name\"`", formatStructTag("name", nonNull(scalar(introspection.ScalarString))))
+	require.Equal(t, "`json:\"value,omitempty\" yaml:\"value,omitempty\" bson:\"value,omitempty\"`", formatStructTag("value", scalar(introspection.ScalarString)))
+
+	require.True(t, IsValidStructTag("yaml"))
+	require.False(t, IsValidStructTag("xml"))
+}
+
+func TestNullableOutputPointers(t *testing.T) {
+	container := &introspection.Type{Kind: introspection.TypeKindObject, Name: "Container"}
+	container.Fields = []*introspection.Field{
+		{Name: "id", TypeRef: nonNull(scalar("ContainerID"))},
+		{Name: "stdout", TypeRef: nonNull(scalar(introspection.ScalarString))},
+		{Name: "envVariable", TypeRef: scalar(introspection.ScalarString)},
+		{Name: "exitCode", TypeRef: scalar(introspection.ScalarInt)},
+		{Name: "rootfsId", TypeRef: scalar("DirectoryID")},
+		{Name: "sync", TypeRef: scalar("ContainerID")},
+	}
+	for _, f := range container.Fields {
+		f.ParentObject = container
+	}
+	generator.SetSchema(&introspection.Schema{Types: introspection.Types{container}})
+	t.Cleanup(func() {
+		generator.SetSchema(nil)
+		generator.SetConfig(generator.Config{})
+	})
+
+	render := func() string {
+		var b bytes.Buffer
+		require.NoError(t, Object.Execute(&b, container))
+		_, err := parser.ParseFile(token.NewFileSet(), "", "package test\n"+b.String(), 0)
+		require.NoError(t, err)
+		return b.String()
+	}
+
+	// Nullable scalars are returned as values by default.
+	src := render()
+	require.Contains(t, src, "func (r *Container) EnvVariable(ctx context.Context) (string, error)")
+	require.Contains(t, src, "func (r *Container) RootfsID(ctx context.Context) (DirectoryID, error)")
+
+	generator.SetConfig(generator.Config{NullableOutputPointers: true})
+	src = render()
+	for _, signature := range []string{
+		// Non-null fields remain values.
+		"func (r *Container) ID(ctx context.Context) (ContainerID, error)",
+		"func (r *Container) Stdout(ctx context.Context) (string, error)",
+		// Nullable scalars and IDs are pointers, returned from the cache as-is.
+		"func (r *Container) EnvVariable(ctx context.Context) (*string, error)",
+		"func (r *Container) ExitCode(ctx context.Context) (*int, error)",
+		"func (r *Container) RootfsID(ctx context.Context) (*DirectoryID, error)",
+		// IDs converted into their object aren't concerned.
+		"func (r *Container) Sync(ctx context.Context) (*Container, error)",
+	} {
+		require.Contains(t, src, signature)
+	}
+	require.Contains(t, src, "return r.envVariable, nil")
+	require.Contains(t, src, "return *r.stdout, nil")
+}
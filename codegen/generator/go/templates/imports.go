@@ -0,0 +1,76 @@
+package templates
+
+import (
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// _imports holds the packages required by the formatted types and the
+// executed templates.
+var _imports = map[string]struct{}{}
+
+// ResetImports forgets the packages recorded so far, before generating a
+// new file.
+func ResetImports() {
+	_imports = map[string]struct{}{}
+}
+
+// Imports returns the sorted packages required by the formatted types and
+// the executed templates.
+func Imports() []string {
+	imports := make([]string, 0, len(_imports))
+	for importPath := range _imports {
+		imports = append(imports, importPath)
+	}
+	sort.Strings(imports)
+	return imports
+}
+
+// addImport records a package required by the generated code.
+// It returns an empty string to be usable from templates.
+func addImport(importPath string) string {
+	if importPath != "" {
+		_imports[importPath] = struct{}{}
+	}
+	return ""
+}
+
+var (
+	majorVersionElem   = regexp.MustCompile(`^v[0-9]+$`)
+	majorVersionSuffix = regexp.MustCompile(`\.v[0-9]+$`)
+)
+
+// packageName returns the name the generated code refers to a package by:
+// the last element of its path without its major version, nor the
+// characters invalid in identifiers. The package is imported with that
+// name when it's not the last element of its path (see formatImport).
+// Example: `gopkg.in/yaml.v3` -> `yaml`, `github.com/foo/bar/v2` -> `bar`
+func packageName(importPath string) string {
+	elems := strings.Split(importPath, "/")
+	name := elems[len(elems)-1]
+	if len(elems) > 1 && majorVersionElem.MatchString(name) {
+		name = elems[len(elems)-2]
+	}
+	name = majorVersionSuffix.ReplaceAllString(name, "")
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			return r
+		}
+		return -1
+	}, name)
+	return prefixDigit(name, "_")
+}
+
+// formatImport formats the import of a package, named after packageName
+// if it's not the last element of its path.
+// Example: `net/url` -> `"net/url"`, `gopkg.in/yaml.v3` -> `yaml "gopkg.in/yaml.v3"`
+func formatImport(importPath string) string {
+	if name := packageName(importPath); name != path.Base(importPath) {
+		return name + " " + strconv.Quote(importPath)
+	}
+	return strconv.Quote(importPath)
+}
@@ -6,6 +6,8 @@ package templates
 import (
 	"strings"
 	"unicode"
+
+	"github.com/dagger/dagger/codegen/generator"
 )
 
 // lintName returns a different name if it should be different.
@@ -59,7 +61,7 @@ func lintName(name string) (should string) {
 
 		// [w,i) is a word.
 		word := string(runes[w:i])
-		if u := strings.ToUpper(word); commonInitialisms[u] {
+		if u := strings.ToUpper(word); isInitialism(u) {
 			// Keep consistent case, which is lowercase only at the start.
 			if w == 0 && unicode.IsLower(runes[w]) {
 				u = strings.ToLower(u)
@@ -76,6 +78,23 @@ func lintName(name string) (should string) {
 	return string(runes)
 }
 
+// isInitialism returns true if the upper-cased word is one of the
+// commonInitialisms or of the configured Initialisms, including the ones
+// of the FormatTypeFunc.
+func isInitialism(u string) bool {
+	if commonInitialisms[u] {
+		return true
+	}
+	for _, initialisms := range [][]string{generator.GetConfig().Initialisms, formatTypeFunc.Initialisms} {
+		for _, i := range initialisms {
+			if strings.ToUpper(i) == u {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // commonInitialisms is a set of common initialisms.
 // Only add entries that are highly unlikely to be non-initialisms.
 // For instance, "ID" is fine (Freudian code is rare), but "AND" is not.
@@ -22,6 +22,14 @@ var (
 	objectSource string
 	Object       *template.Template
 
+	//go:embed src/interface.go.tmpl
+	interfaceSource string
+	Interface       *template.Template
+
+	//go:embed src/union.go.tmpl
+	unionSource string
+	Union       *template.Template
+
 	//go:embed src/enum.go.tmpl
 	enumSource string
 	Enum       *template.Template
@@ -49,6 +57,16 @@ func init() {
 		panic(err)
 	}
 
+	Interface, err = template.New("interface").Funcs(funcMap).Parse(interfaceSource)
+	if err != nil {
+		panic(err)
+	}
+
+	Union, err = template.New("union").Funcs(funcMap).Parse(unionSource)
+	if err != nil {
+		panic(err)
+	}
+
 	Enum, err = template.New("enum").Funcs(funcMap).Parse(enumSource)
 	if err != nil {
 		panic(err)
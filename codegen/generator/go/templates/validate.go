@@ -0,0 +1,121 @@
+package templates
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dagger/dagger/codegen/generator"
+	"github.com/dagger/dagger/codegen/introspection"
+)
+
+// validateFields returns the statements of the Validate method of an input
+// object: its required fields left to the zero value are reported at once,
+// then its nested input objects are validated.
+// The numbers, booleans, lists and maps aren't checked since their zero
+// value is a legit one.
+// The fields of `@oneOf` input objects are all nullable: instead, exactly
+// one of them must be set.
+func validateFields(t *introspection.Type) string {
+	var checks, nested []string
+	for _, f := range t.InputFields {
+		name := "r." + formatFieldName(f.Name, f.TypeRef)
+		typ := formatInputFieldType(f)
+		switch {
+		case isOneOf(t):
+			checks = append(checks, fmt.Sprintf("if !querybuilder.IsZeroValue(%s) {\nset = append(set, %q)\n}", name, f.Name))
+		case !f.TypeRef.IsOptional() && !hasLegitZeroValue(typ):
+			checks = append(checks, fmt.Sprintf("if querybuilder.IsZeroValue(%s) {\nmissing = append(missing, %q)\n}", name, f.Name))
+		}
+		nested = append(nested, validateNested(name, typ, t.Name+"."+f.Name, nil, 0)...)
+	}
+
+	var stmts []string
+	switch {
+	case isOneOf(t) && len(checks) > 0:
+		stmts = append(stmts, "var set []string")
+		stmts = append(stmts, checks...)
+		stmts = append(stmts,
+			fmt.Sprintf("if len(set) == 0 {\nreturn fmt.Errorf(\"%s: exactly one field must be set\")\n}", t.Name),
+			fmt.Sprintf("if len(set) > 1 {\nreturn fmt.Errorf(\"%s: exactly one field must be set, got %%s\", strings.Join(set, \", \"))\n}", t.Name))
+	case len(checks) > 0:
+		stmts = append(stmts, "var missing []string")
+		stmts = append(stmts, checks...)
+		stmts = append(stmts, fmt.Sprintf("if len(missing) > 0 {\nreturn fmt.Errorf(\"%s: missing required fields: %%s\", strings.Join(missing, \", \"))\n}", t.Name))
+	}
+	stmts = append(stmts, nested...)
+	return strings.Join(stmts, "\n")
+}
+
+// validateNested returns the statements validating the input objects held
+// by expr according to its Go type, wrapping their errors with their path.
+// Example: `r.And`, `[]Filter`, `Filter.and` ->
+//
+//	for i0 := range r.And {
+//		if err := r.And[i0].Validate(); err != nil {
+//			return fmt.Errorf("Filter.and[%d]: %w", i0, err)
+//		}
+//	}
+func validateNested(expr, typ, path string, indexes []string, depth int) []string {
+	switch {
+	case hasValidate(strings.TrimPrefix(typ, "*")):
+		args := append(indexes[:len(indexes):len(indexes)], "err")
+		return []string{fmt.Sprintf("if err := %s.Validate(); err != nil {\nreturn fmt.Errorf(\"%s: %%w\", %s)\n}",
+			operand(expr), path, strings.Join(args, ", "))}
+
+	case strings.HasPrefix(typ, "*[]"):
+		stmts := validateNested("*"+expr, typ[1:], path, indexes, depth)
+		if len(stmts) == 0 {
+			return nil
+		}
+		return []string{fmt.Sprintf("if %s != nil {\n%s\n}", expr, strings.Join(stmts, "\n"))}
+
+	case strings.HasPrefix(typ, "[]"):
+		i := fmt.Sprintf("i%d", depth)
+		stmts := validateNested(operand(expr)+"["+i+"]", typ[2:], path+"[%d]", append(indexes[:len(indexes):len(indexes)], i), depth+1)
+		if len(stmts) == 0 {
+			return nil
+		}
+		return []string{fmt.Sprintf("for %s := range %s {\n%s\n}", i, expr, strings.Join(stmts, "\n"))}
+	}
+	return nil
+}
+
+// hasValidate returns true if a Go type is a generated input object with a
+// Validate method.
+func hasValidate(typ string) bool {
+	t := generatedType(typ)
+	if t == nil || t.Kind != introspection.TypeKindInputObject {
+		return false
+	}
+	return generator.GetConfig().ValidateInputs || isOneOf(t)
+}
+
+// isOneOf returns true if exactly one field of an input object must be set,
+// as required by the `@oneOf` directive.
+func isOneOf(t *introspection.Type) bool {
+	if t.Kind != introspection.TypeKindInputObject {
+		return false
+	}
+	if t.IsOneOf {
+		return true
+	}
+	for _, name := range generator.GetConfig().OneOfInputs {
+		if name == t.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// hasLegitZeroValue returns true if the zero value of a Go type can't be
+// told apart from a value set on purpose.
+func hasLegitZeroValue(typ string) bool {
+	switch typ {
+	case "bool",
+		"int", "int8", "int16", "int32", "int64",
+		"uint", "uint8", "uint16", "uint32", "uint64",
+		"float32", "float64":
+		return true
+	}
+	return strings.HasPrefix(typ, "[]") || strings.HasPrefix(typ, "map[")
+}
@@ -0,0 +1,181 @@
+package templates
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dagger/dagger/codegen/generator"
+	"github.com/dagger/dagger/codegen/introspection"
+)
+
+func TestHasLegitZeroValue(t *testing.T) {
+	for _, typ := range []string{"bool", "int", "float64", "[]string", "map[string]string"} {
+		require.True(t, hasLegitZeroValue(typ), typ)
+	}
+	for _, typ := range []string{"string", "Platform", "*Container", "json.RawMessage"} {
+		require.False(t, hasLegitZeroValue(typ), typ)
+	}
+}
+
+func TestIsOneOf(t *testing.T) {
+	generator.SetConfig(generator.Config{OneOfInputs: []string{"Selector"}})
+	t.Cleanup(func() {
+		generator.SetConfig(generator.Config{})
+	})
+
+	require.True(t, isOneOf(&introspection.Type{Kind: introspection.TypeKindInputObject, Name: "Filter", IsOneOf: true}))
+	require.True(t, isOneOf(&introspection.Type{Kind: introspection.TypeKindInputObject, Name: "Selector"}))
+	require.False(t, isOneOf(&introspection.Type{Kind: introspection.TypeKindInputObject, Name: "Range"}))
+	require.False(t, isOneOf(&introspection.Type{Kind: introspection.TypeKindObject, Name: "Selector"}))
+}
+
+// TestValidate builds the generated input objects along with a test
+// validating them.
+func TestValidate(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds the generated code")
+	}
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go isn't installed")
+	}
+
+	input := func(name string) *introspection.TypeRef {
+		return &introspection.TypeRef{Kind: introspection.TypeKindInputObject, Name: name}
+	}
+	filter := &introspection.Type{
+		Kind: introspection.TypeKindInputObject,
+		Name: "Filter",
+		InputFields: introspection.InputValues{
+			{Name: "name", TypeRef: nonNull(scalar(introspection.ScalarString))},
+			{Name: "exact", TypeRef: nonNull(scalar(introspection.ScalarBoolean))},
+			{Name: "tags", TypeRef: nonNull(listOf(nonNull(scalar(introspection.ScalarString))))},
+			{Name: "not", TypeRef: input("Filter")},
+			{Name: "and", TypeRef: listOf(nonNull(input("Filter")))},
+			{Name: "range", TypeRef: nonNull(input("Range"))},
+		},
+	}
+	rng := &introspection.Type{
+		Kind: introspection.TypeKindInputObject,
+		Name: "Range",
+		InputFields: introspection.InputValues{
+			{Name: "from", TypeRef: nonNull(scalar(introspection.ScalarString))},
+			{Name: "to", TypeRef: scalar(introspection.ScalarString)},
+		},
+	}
+	selector := &introspection.Type{
+		Kind:    introspection.TypeKindInputObject,
+		Name:    "Selector",
+		IsOneOf: true,
+		InputFields: introspection.InputValues{
+			{Name: "name", TypeRef: scalar(introspection.ScalarString)},
+			{Name: "ids", TypeRef: listOf(nonNull(scalar(introspection.ScalarString)))},
+			{Name: "filter", TypeRef: input("Filter")},
+		},
+	}
+	schema := &introspection.Schema{Types: introspection.Types{filter, rng, selector}}
+	generator.SetSchemaParents(schema)
+	generator.SetSchema(schema)
+	generator.SetConfig(generator.Config{ValidateInputs: true})
+	t.Cleanup(func() {
+		generator.SetSchema(nil)
+		generator.SetConfig(generator.Config{})
+	})
+
+	src := bytes.NewBufferString(`package validate
+
+import (
+	"fmt"
+	"strings"
+
+	"validate/querybuilder"
+)
+`)
+	for _, input := range schema.Types {
+		require.NoError(t, Input.Execute(src, input))
+	}
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod": "module validate\n\ngo 1.20\n",
+		"api.go": src.String(),
+		"querybuilder/querybuilder.go": `package querybuilder
+
+import "reflect"
+
+func IsZeroValue(value any) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Pointer:
+		return v.IsNil()
+	case reflect.Slice, reflect.Array:
+		return v.Len() == 0
+	default:
+		return v.IsZero()
+	}
+}
+`,
+		"api_test.go": `package validate
+
+import "testing"
+
+func TestValidate(t *testing.T) {
+	for _, c := range []struct {
+		filter *Filter
+		err    string
+	}{
+		{nil, ""},
+		{&Filter{Name: "a", Range: Range{From: "0"}}, ""},
+		{&Filter{}, "Filter: missing required fields: name, range"},
+		{&Filter{Name: "a", Range: Range{To: new(string)}}, "Filter.range: Range: missing required fields: from"},
+		{&Filter{Name: "a", Range: Range{From: "0"}, Not: &Filter{Range: Range{From: "0"}}}, "Filter.not: Filter: missing required fields: name"},
+		{&Filter{Name: "a", Range: Range{From: "0"}, And: []Filter{{Name: "b", Range: Range{From: "0"}}, {Name: "c"}}}, "Filter.and[1]: Filter: missing required fields: range"},
+	} {
+		check(t, c.filter.Validate(), c.err)
+	}
+}
+
+func TestValidateOneOf(t *testing.T) {
+	byName := NewSelectorWithName("a")
+	check(t, byName.Validate(), "")
+	byIDs := NewSelectorWithIds([]string{"a"})
+	check(t, byIDs.Validate(), "")
+	byFilter := NewSelectorWithFilter(Filter{Name: "a"})
+	check(t, byFilter.Validate(), "Selector.filter: Filter: missing required fields: range")
+
+	check(t, (&Selector{}).Validate(), "Selector: exactly one field must be set")
+	check(t, (&Selector{Ids: []string{}}).Validate(), "Selector: exactly one field must be set")
+	name := "a"
+	check(t, (&Selector{Name: &name, Ids: []string{"a"}}).Validate(), "Selector: exactly one field must be set, got name, ids")
+}
+
+func check(t *testing.T, err error, expected string) {
+	t.Helper()
+	switch {
+	case expected == "" && err != nil:
+		t.Errorf("unexpected error: %s", err)
+	case expected != "" && (err == nil || err.Error() != expected):
+		t.Errorf("expected %q, got %v", expected, err)
+	}
+}
+`,
+	}
+	for name, contents := range files {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0o700))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(contents), 0o600))
+	}
+
+	cmd := exec.Command(goBin, "test", "./...")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOTOOLCHAIN=local", "GOWORK=off")
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+}
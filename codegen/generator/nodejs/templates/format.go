@@ -8,11 +8,19 @@ import (
 // to format GraphQL type into Typescript.
 type FormatTypeFunc struct{}
 
+var _ generator.FormatTypeFuncs = &FormatTypeFunc{}
+
 func (f *FormatTypeFunc) FormatKindList(representation string) string {
 	representation += "[]"
 	return representation
 }
 
+// FormatNullable keeps nullable types as-is: optional arguments are
+// marked as such in their options type.
+func (f *FormatTypeFunc) FormatNullable(representation string, t generator.NullableType) string {
+	return representation
+}
+
 func (f *FormatTypeFunc) FormatKindScalarString(representation string) string {
 	representation += "string"
 	return representation
@@ -53,6 +61,16 @@ func (f *FormatTypeFunc) FormatKindObject(representation string, refName string)
 	return representation
 }
 
+func (f *FormatTypeFunc) FormatKindInterface(representation string, refName string) string {
+	representation += formatName(refName)
+	return representation
+}
+
+func (f *FormatTypeFunc) FormatKindUnion(representation string, refName string) string {
+	representation += formatName(refName)
+	return representation
+}
+
 func (f *FormatTypeFunc) FormatKindInputObject(representation string, refName string) string {
 	representation += formatName(refName)
 	return representation
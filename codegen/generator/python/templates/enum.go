@@ -0,0 +1,75 @@
+package templates
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dagger/dagger/codegen/introspection"
+)
+
+// pythonKeywords are the reserved words which can't be used as identifiers.
+var pythonKeywords = []string{
+	"False", "None", "True", "and", "as", "assert", "async", "await",
+	"break", "class", "continue", "def", "del", "elif", "else", "except",
+	"finally", "for", "from", "global", "if", "import", "in", "is",
+	"lambda", "nonlocal", "not", "or", "pass", "raise", "return", "try",
+	"while", "with", "yield",
+}
+
+// FormatEnum formats a GraphQL enum into the `enum.Enum` subclass
+// referenced by FormatKindEnum, its members holding the GraphQL values.
+// Example: `enum CacheSharingMode { LOCKED SHARED }` ->
+//
+//	class CacheSharingMode(enum.Enum):
+//	    LOCKED = "LOCKED"
+//	    SHARED = "SHARED"
+func FormatEnum(t introspection.Type) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "class %s(enum.Enum):\n", formatName(t.Name))
+	if desc := strings.TrimSpace(t.Description); desc != "" {
+		fmt.Fprintf(&b, "    %s\n", docstring(desc))
+		if len(t.EnumValues) > 0 {
+			b.WriteString("\n")
+		}
+	}
+	for _, v := range t.EnumValues {
+		if desc := strings.TrimSpace(v.Description); desc != "" {
+			for _, line := range strings.Split(desc, "\n") {
+				fmt.Fprintf(&b, "    #: %s\n", strings.TrimSpace(line))
+			}
+		}
+		fmt.Fprintf(&b, "    %s = %q\n", formatEnumMember(v.Name), v.Name)
+	}
+	if len(t.EnumValues) == 0 && strings.TrimSpace(t.Description) == "" {
+		b.WriteString("    pass\n")
+	}
+	return b.String()
+}
+
+// formatEnumMember formats the name of an enum member, which are suffixed
+// with an underscore when they're Python keywords.
+// Example: `None` -> `None_`
+func formatEnumMember(s string) string {
+	for _, kw := range pythonKeywords {
+		if s == kw {
+			return s + "_"
+		}
+	}
+	return s
+}
+
+// docstring formats a description as a docstring.
+// Example: `Sharing mode.` -> `"""Sharing mode."""`
+func docstring(s string) string {
+	s = strings.ReplaceAll(s, `"""`, `\"\"\"`)
+	lines := strings.Split(s, "\n")
+	if len(lines) == 1 {
+		return `"""` + s + `"""`
+	}
+	for i := 1; i < len(lines); i++ {
+		if lines[i] = strings.TrimSpace(lines[i]); lines[i] != "" {
+			lines[i] = "    " + lines[i]
+		}
+	}
+	return `"""` + strings.Join(lines, "\n") + "\n    " + `"""`
+}
@@ -0,0 +1,86 @@
+package templates
+
+import (
+	"github.com/dagger/dagger/codegen/generator"
+)
+
+// FormatTypeFunc is an implementation of generator.FormatTypeFuncs interface
+// to format GraphQL type into Python type hints.
+type FormatTypeFunc struct{}
+
+var _ generator.FormatTypeFuncs = &FormatTypeFunc{}
+
+func (f *FormatTypeFunc) FormatKindList(representation string) string {
+	representation = "List[" + representation + "]"
+	return representation
+}
+
+func (f *FormatTypeFunc) FormatNullable(representation string, t generator.NullableType) string {
+	representation = "Optional[" + representation + "]"
+	return representation
+}
+
+func (f *FormatTypeFunc) FormatKindScalarString(representation string) string {
+	representation += "str"
+	return representation
+}
+
+func (f *FormatTypeFunc) FormatKindScalarInt(representation string) string {
+	representation += "int"
+	return representation
+}
+
+func (f *FormatTypeFunc) FormatKindScalarFloat(representation string) string {
+	representation += "float"
+	return representation
+}
+
+func (f *FormatTypeFunc) FormatKindScalarBoolean(representation string) string {
+	representation += "bool"
+	return representation
+}
+
+func (f *FormatTypeFunc) FormatKindScalarDefault(representation string, refName string, input bool) string {
+	if alias, ok := generator.CustomScalar[refName]; ok && input {
+		representation += alias
+	} else {
+		representation += refName
+	}
+
+	return representation
+}
+
+func (f *FormatTypeFunc) FormatKindObject(representation string, refName string) string {
+	representation += formatName(refName)
+	return representation
+}
+
+func (f *FormatTypeFunc) FormatKindInterface(representation string, refName string) string {
+	representation += formatName(refName)
+	return representation
+}
+
+func (f *FormatTypeFunc) FormatKindUnion(representation string, refName string) string {
+	representation += formatName(refName)
+	return representation
+}
+
+func (f *FormatTypeFunc) FormatKindInputObject(representation string, refName string) string {
+	representation += formatName(refName)
+	return representation
+}
+
+// FormatKindEnum references the `enum.Enum` subclass generated for the
+// enum, see FormatEnum.
+func (f *FormatTypeFunc) FormatKindEnum(representation string, refName string) string {
+	representation += refName
+	return representation
+}
+
+// formatName formats a GraphQL name (e.g. object, field, arg) into a Python equivalent
+func formatName(s string) string {
+	if s == generator.QueryStructName {
+		return generator.QueryStructClientName
+	}
+	return s
+}
@@ -0,0 +1,88 @@
+package templates
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dagger/dagger/codegen/generator"
+	"github.com/dagger/dagger/codegen/introspection"
+)
+
+func TestFormatType(t *testing.T) {
+	commonFunc := generator.NewCommonFunctions(&FormatTypeFunc{})
+
+	ref := func(kind introspection.TypeKind, name string) *introspection.TypeRef {
+		return &introspection.TypeRef{Kind: kind, Name: name}
+	}
+	listOf := func(r *introspection.TypeRef) *introspection.TypeRef {
+		return &introspection.TypeRef{Kind: introspection.TypeKindList, OfType: r}
+	}
+	nonNull := func(r *introspection.TypeRef) *introspection.TypeRef {
+		return &introspection.TypeRef{Kind: introspection.TypeKindNonNull, OfType: r}
+	}
+
+	cases := []struct {
+		name   string
+		ref    *introspection.TypeRef
+		input  string
+		output string
+	}{
+		{"String!", nonNull(ref(introspection.TypeKindScalar, "String")), "str", "str"},
+		{"Int!", nonNull(ref(introspection.TypeKindScalar, "Int")), "int", "int"},
+		{"Float!", nonNull(ref(introspection.TypeKindScalar, "Float")), "float", "float"},
+		{"Boolean!", nonNull(ref(introspection.TypeKindScalar, "Boolean")), "bool", "bool"},
+		{"String", ref(introspection.TypeKindScalar, "String"), "Optional[str]", "Optional[str]"},
+		{"[[String!]!]!", nonNull(listOf(nonNull(listOf(nonNull(ref(introspection.TypeKindScalar, "String")))))), "List[List[str]]", "List[List[str]]"},
+		{"[String!]", listOf(nonNull(ref(introspection.TypeKindScalar, "String"))), "Optional[List[str]]", "Optional[List[str]]"},
+		{"[String]!", nonNull(listOf(ref(introspection.TypeKindScalar, "String"))), "List[Optional[str]]", "List[Optional[str]]"},
+		{"ContainerID!", nonNull(ref(introspection.TypeKindScalar, "ContainerID")), "Container", "ContainerID"},
+		{"Query!", nonNull(ref(introspection.TypeKindObject, "Query")), "Client", "Client"},
+		{"CacheSharingMode", ref(introspection.TypeKindEnum, "CacheSharingMode"), "Optional[CacheSharingMode]", "Optional[CacheSharingMode]"},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			require.Equal(t, c.input, commonFunc.FormatInputType(c.ref))
+			require.Equal(t, c.output, commonFunc.FormatOutputType(c.ref))
+		})
+	}
+}
+
+func TestFormatEnum(t *testing.T) {
+	mode := introspection.Type{
+		Kind:        introspection.TypeKindEnum,
+		Name:        "CacheSharingMode",
+		Description: "Sharing mode of the cache volume.",
+		EnumValues: []introspection.EnumValue{
+			{Name: "LOCKED", Description: "Shares the cache volume amongst many build pipelines,\nbut will serialize the writes"},
+			{Name: "SHARED"},
+		},
+	}
+	require.Equal(t, `class CacheSharingMode(enum.Enum):
+    """Sharing mode of the cache volume."""
+
+    #: Shares the cache volume amongst many build pipelines,
+    #: but will serialize the writes
+    LOCKED = "LOCKED"
+    SHARED = "SHARED"
+`, FormatEnum(mode))
+
+	keywords := introspection.Type{
+		Kind:        introspection.TypeKindEnum,
+		Name:        "Literal",
+		Description: "A literal.\n\nEither none or true.",
+		EnumValues:  []introspection.EnumValue{{Name: "None"}, {Name: "True"}},
+	}
+	require.Equal(t, `class Literal(enum.Enum):
+    """A literal.
+
+    Either none or true.
+    """
+
+    None_ = "None"
+    True_ = "True"
+`, FormatEnum(keywords))
+
+	require.Equal(t, "class Empty(enum.Enum):\n    pass\n", FormatEnum(introspection.Type{Kind: introspection.TypeKindEnum, Name: "Empty"}))
+}
@@ -2,6 +2,7 @@ package introspection
 
 import (
 	_ "embed"
+	"strings"
 )
 
 // Query is the query generated by graphiql to determine type information
@@ -9,6 +10,10 @@ import (
 //go:embed introspection.graphql
 var Query string
 
+// QueryWithoutOneOf is Query without the `isOneOf` field of the types, for
+// the servers which don't support the `@oneOf` directive.
+var QueryWithoutOneOf = strings.Replace(Query, "\n  isOneOf\n", "\n", 1)
+
 // Response is the introspection query response
 type Response struct {
 	Schema *Schema `json:"__schema"`
@@ -68,15 +73,23 @@ const (
 	ScalarFloat   = Scalar("Float")
 	ScalarString  = Scalar("String")
 	ScalarBoolean = Scalar("Boolean")
+	ScalarID      = Scalar("ID")
 )
 
 type Type struct {
-	Kind        TypeKind     `json:"kind"`
-	Name        string       `json:"name"`
-	Description string       `json:"description,omitempty"`
-	Fields      []*Field     `json:"fields,omitempty"`
-	InputFields []InputValue `json:"inputFields,omitempty"`
-	EnumValues  []EnumValue  `json:"enumValues,omitempty"`
+	Kind        TypeKind    `json:"kind"`
+	Name        string      `json:"name"`
+	Description string      `json:"description,omitempty"`
+	Fields      []*Field    `json:"fields,omitempty"`
+	InputFields InputValues `json:"inputFields,omitempty"`
+	EnumValues  []EnumValue `json:"enumValues,omitempty"`
+
+	Interfaces    []*TypeRef `json:"interfaces,omitempty"`
+	PossibleTypes []*TypeRef `json:"possibleTypes,omitempty"`
+
+	// IsOneOf is true for the input objects with the `@oneOf` directive,
+	// when reported by the introspected server.
+	IsOneOf bool `json:"isOneOf,omitempty"`
 }
 
 type Types []*Type
@@ -136,6 +149,28 @@ func (r TypeRef) IsObject() bool {
 	return false
 }
 
+func (r TypeRef) IsInterface() bool {
+	ref := r
+	if r.Kind == TypeKindNonNull {
+		ref = *ref.OfType
+	}
+	if ref.Kind == TypeKindInterface {
+		return true
+	}
+	return false
+}
+
+func (r TypeRef) IsUnion() bool {
+	ref := r
+	if r.Kind == TypeKindNonNull {
+		ref = *ref.OfType
+	}
+	if ref.Kind == TypeKindUnion {
+		return true
+	}
+	return false
+}
+
 func (r TypeRef) IsList() bool {
 	ref := r
 	if r.Kind == TypeKindNonNull {
@@ -163,6 +198,9 @@ type InputValue struct {
 	Description  string   `json:"description"`
 	DefaultValue *string  `json:"defaultValue"`
 	TypeRef      *TypeRef `json:"type"`
+
+	// ParentObject is only set for the fields of input objects.
+	ParentObject *Type `json:"-"`
 }
 
 type EnumValue struct {
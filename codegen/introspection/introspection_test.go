@@ -0,0 +1,47 @@
+package introspection
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuery(t *testing.T) {
+	require.Contains(t, Query, "isOneOf")
+	require.NotContains(t, QueryWithoutOneOf, "isOneOf")
+}
+
+func TestDecodeOneOf(t *testing.T) {
+	var resp Response
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"__schema": {
+			"queryType": {"name": "Query"},
+			"types": [
+				{
+					"kind": "INPUT_OBJECT",
+					"name": "Selector",
+					"inputFields": [
+						{"name": "name", "type": {"kind": "SCALAR", "name": "String"}},
+						{"name": "id", "type": {"kind": "SCALAR", "name": "ID"}}
+					],
+					"isOneOf": true
+				},
+				{
+					"kind": "INPUT_OBJECT",
+					"name": "Range",
+					"inputFields": [
+						{"name": "from", "type": {"kind": "NON_NULL", "ofType": {"kind": "SCALAR", "name": "Int"}}}
+					],
+					"isOneOf": false
+				},
+				{"kind": "OBJECT", "name": "Query", "fields": [], "isOneOf": null}
+			]
+		}
+	}`), &resp))
+
+	require.True(t, resp.Schema.Types.Get("Selector").IsOneOf)
+	require.Len(t, resp.Schema.Types.Get("Selector").InputFields, 2)
+	require.False(t, resp.Schema.Types.Get("Range").IsOneOf)
+	require.False(t, resp.Schema.Types.Get("Query").IsOneOf)
+}
@@ -13,10 +13,12 @@ type Visitor struct {
 type VisitFunc func(*Type) error
 
 type VisitHandlers struct {
-	Scalar VisitFunc
-	Object VisitFunc
-	Input  VisitFunc
-	Enum   VisitFunc
+	Scalar    VisitFunc
+	Object    VisitFunc
+	Interface VisitFunc
+	Union     VisitFunc
+	Input     VisitFunc
+	Enum      VisitFunc
 }
 
 func (v *Visitor) Run() error {
@@ -45,6 +47,14 @@ func (v *Visitor) Run() error {
 			Kind:    TypeKindObject,
 			Handler: v.handlers.Object,
 		},
+		{
+			Kind:    TypeKindInterface,
+			Handler: v.handlers.Interface,
+		},
+		{
+			Kind:    TypeKindUnion,
+			Handler: v.handlers.Union,
+		},
 		{
 			Kind:    TypeKindEnum,
 			Handler: v.handlers.Enum,
@@ -4,6 +4,9 @@ package dagger
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 
 	"dagger.io/dagger/internal/querybuilder"
 	"github.com/Khan/genqlient/graphql"
@@ -47,6 +50,15 @@ type BuildArg struct {
 	Value string `json:"value"`
 }
 
+// NewBuildArg creates a BuildArg from its required fields.
+func NewBuildArg(name string, value string) BuildArg {
+	r := BuildArg{
+		Name:  name,
+		Value: value,
+	}
+	return r
+}
+
 // Key value object that represents a Pipeline label.
 type PipelineLabel struct {
 	// Label name.
@@ -56,6 +68,15 @@ type PipelineLabel struct {
 	Value string `json:"value"`
 }
 
+// NewPipelineLabel creates a PipelineLabel from its required fields.
+func NewPipelineLabel(name string, value string) PipelineLabel {
+	r := PipelineLabel{
+		Name:  name,
+		Value: value,
+	}
+	return r
+}
+
 // A directory whose contents persist across runs.
 type CacheVolume struct {
 	q *querybuilder.Selection
@@ -95,6 +116,27 @@ func (r *CacheVolume) XXX_GraphQLID(ctx context.Context) (string, error) {
 	return string(id), nil
 }
 
+// MarshalJSON encodes a CacheVolume as its ID. The ID is only known without
+// querying it for a CacheVolume decoded from JSON or returned in a list:
+// marshal the result of ID otherwise.
+func (r *CacheVolume) MarshalJSON() ([]byte, error) {
+	if r.id == nil {
+		return nil, errors.New("the ID of the CacheVolume isn't known, marshal the result of ID instead")
+	}
+	return json.Marshal(*r.id)
+}
+
+// UnmarshalJSON decodes a CacheVolume from its ID. Without a client, the
+// CacheVolume can only be passed as an argument or have its ID retrieved.
+func (r *CacheVolume) UnmarshalJSON(data []byte) error {
+	var id CacheID
+	if err := json.Unmarshal(data, &id); err != nil {
+		return err
+	}
+	*r = CacheVolume{id: &id}
+	return nil
+}
+
 // An OCI-compatible container, also known as a docker container.
 type Container struct {
 	q *querybuilder.Selection
@@ -129,18 +171,29 @@ type ContainerBuildOpts struct {
 	// Path to the Dockerfile to use.
 	//
 	// Default: './Dockerfile'.
-	Dockerfile string
+	//
+	// Optional; the zero value means not set.
+	Dockerfile string `json:"dockerfile,omitempty"`
 	// Additional build arguments.
-	BuildArgs []BuildArg
+	//
+	// Optional; nil means not set.
+	BuildArgs []BuildArg `json:"buildArgs,omitempty"`
 	// Target build stage to build.
-	Target string
+	//
+	// Optional; the zero value means not set.
+	Target string `json:"target,omitempty"`
 	// Secrets to pass to the build.
 	//
 	// They will be mounted at /run/secrets/[secret-name].
-	Secrets []*Secret
+	//
+	// Optional; nil means not set.
+	Secrets []*Secret `json:"secrets,omitempty"`
 }
 
 // Initializes this container from a Dockerfile build.
+//
+// Parameters:
+//   - context: Directory context used by the Dockerfile.
 func (r *Container) Build(context *Directory, opts ...ContainerBuildOpts) *Container {
 	q := r.q.Select("build")
 	for i := len(opts) - 1; i >= 0; i-- {
@@ -170,6 +223,8 @@ func (r *Container) Build(context *Directory, opts ...ContainerBuildOpts) *Conta
 }
 
 // Retrieves default arguments for future commands.
+//
+// Nullable; nil means null.
 func (r *Container) DefaultArgs(ctx context.Context) ([]string, error) {
 	q := r.q.Select("defaultArgs")
 
@@ -182,6 +237,9 @@ func (r *Container) DefaultArgs(ctx context.Context) ([]string, error) {
 // Retrieves a directory at the given path.
 //
 // Mounts are included.
+//
+// Parameters:
+//   - path: The path of the directory to retrieve (e.g., "./src").
 func (r *Container) Directory(path string) *Directory {
 	q := r.q.Select("directory")
 	q = q.Arg("path", path)
@@ -195,9 +253,13 @@ func (r *Container) Directory(path string) *Directory {
 // ContainerEndpointOpts contains options for Container.Endpoint
 type ContainerEndpointOpts struct {
 	// The exposed port number for the endpoint
-	Port int
+	//
+	// Optional; the zero value means not set.
+	Port int `json:"port,omitempty"`
 	// Return a URL with the given scheme, eg. http for http://
-	Scheme string
+	//
+	// Optional; the zero value means not set.
+	Scheme string `json:"scheme,omitempty"`
 }
 
 // Retrieves an endpoint that clients can use to reach this container.
@@ -230,6 +292,8 @@ func (r *Container) Endpoint(ctx context.Context, opts ...ContainerEndpointOpts)
 }
 
 // Retrieves entrypoint to be prepended to the arguments of all commands.
+//
+// Nullable; nil means null.
 func (r *Container) Entrypoint(ctx context.Context) ([]string, error) {
 	q := r.q.Select("entrypoint")
 
@@ -240,6 +304,11 @@ func (r *Container) Entrypoint(ctx context.Context) ([]string, error) {
 }
 
 // Retrieves the value of the specified environment variable.
+//
+// Nullable; null is returned as the zero value.
+//
+// Parameters:
+//   - name: The name of the environment variable to retrieve (e.g., "PATH").
 func (r *Container) EnvVariable(ctx context.Context, name string) (string, error) {
 	if r.envVariable != nil {
 		return *r.envVariable, nil
@@ -260,8 +329,8 @@ func (r *Container) EnvVariables(ctx context.Context) ([]EnvVariable, error) {
 	q = q.Select("name value")
 
 	type envVariables struct {
-		Name  string
-		Value string
+		Name  string `json:"name"`
+		Value string `json:"value"`
 	}
 
 	convert := func(fields []envVariables) []EnvVariable {
@@ -289,23 +358,33 @@ func (r *Container) EnvVariables(ctx context.Context) ([]EnvVariable, error) {
 type ContainerExportOpts struct {
 	// Identifiers for other platform specific containers.
 	// Used for multi-platform image.
-	PlatformVariants []*Container
+	//
+	// Optional; nil means not set.
+	PlatformVariants []*Container `json:"platformVariants,omitempty"`
 	// Force each layer of the exported image to use the specified compression algorithm.
 	// If this is unset, then if a layer already has a compressed blob in the engine's
 	// cache, that will be used (this can result in a mix of compression algorithms for
 	// different layers). If this is unset and a layer has no compressed blob in the
 	// engine's cache, then it will be compressed using Gzip.
-	ForcedCompression ImageLayerCompression
+	//
+	// Optional; the zero value means not set.
+	ForcedCompression ImageLayerCompression `json:"forcedCompression,omitempty"`
 	// Use the specified media types for the exported image's layers. Defaults to OCI, which
 	// is largely compatible with most recent container runtimes, but Docker may be needed
 	// for older runtimes without OCI support.
-	MediaTypes ImageMediaTypes
+	//
+	// Optional; the zero value means not set.
+	MediaTypes ImageMediaTypes `json:"mediaTypes,omitempty"`
 }
 
 // Writes the container as an OCI tarball to the destination file path on the host for the specified platform variants.
 //
 // Return true on success.
 // It can also publishes platform variants.
+//
+// Parameters:
+//   - path: Host's destination path (e.g., "./tarball").
+//     Path can be relative to the engine's workdir or absolute.
 func (r *Container) Export(ctx context.Context, path string, opts ...ContainerExportOpts) (bool, error) {
 	if r.export != nil {
 		return *r.export, nil
@@ -345,9 +424,9 @@ func (r *Container) ExposedPorts(ctx context.Context) ([]Port, error) {
 	q = q.Select("description port protocol")
 
 	type exposedPorts struct {
-		Description string
-		Port        int
-		Protocol    NetworkProtocol
+		Description string          `json:"description,omitempty"`
+		Port        int             `json:"port"`
+		Protocol    NetworkProtocol `json:"protocol"`
 	}
 
 	convert := func(fields []exposedPorts) []Port {
@@ -374,6 +453,9 @@ func (r *Container) ExposedPorts(ctx context.Context) ([]Port, error) {
 // Retrieves a file at the given path.
 //
 // Mounts are included.
+//
+// Parameters:
+//   - path: The path of the file to retrieve (e.g., "./README.md").
 func (r *Container) File(path string) *File {
 	q := r.q.Select("file")
 	q = q.Arg("path", path)
@@ -385,6 +467,10 @@ func (r *Container) File(path string) *File {
 }
 
 // Initializes this container from a pulled base image.
+//
+// Parameters:
+//   - address: Image's address from its registry.
+//     Formatted as [host]/[user]/[repo]:[tag] (e.g., "docker.io/dagger/dagger:main").
 func (r *Container) From(address string) *Container {
 	q := r.q.Select("from")
 	q = q.Arg("address", address)
@@ -442,7 +528,30 @@ func (r *Container) XXX_GraphQLID(ctx context.Context) (string, error) {
 	return string(id), nil
 }
 
+// MarshalJSON encodes a Container as its ID. The ID is only known without
+// querying it for a Container decoded from JSON or returned in a list:
+// marshal the result of ID otherwise.
+func (r *Container) MarshalJSON() ([]byte, error) {
+	if r.id == nil {
+		return nil, errors.New("the ID of the Container isn't known, marshal the result of ID instead")
+	}
+	return json.Marshal(*r.id)
+}
+
+// UnmarshalJSON decodes a Container from its ID. Without a client, the
+// Container can only be passed as an argument or have its ID retrieved.
+func (r *Container) UnmarshalJSON(data []byte) error {
+	var id ContainerID
+	if err := json.Unmarshal(data, &id); err != nil {
+		return err
+	}
+	*r = Container{id: &id}
+	return nil
+}
+
 // The unique image reference which can only be retrieved immediately after the 'Container.From' call.
+//
+// Nullable; null is returned as the zero value.
 func (r *Container) ImageRef(ctx context.Context) (string, error) {
 	if r.imageRef != nil {
 		return *r.imageRef, nil
@@ -459,13 +568,18 @@ func (r *Container) ImageRef(ctx context.Context) (string, error) {
 type ContainerImportOpts struct {
 	// Identifies the tag to import from the archive, if the archive bundles
 	// multiple tags.
-	Tag string
+	//
+	// Optional; the zero value means not set.
+	Tag string `json:"tag,omitempty"`
 }
 
 // Reads the container from an OCI tarball.
 //
 // NOTE: this involves unpacking the tarball to an OCI store on the host at
 // $XDG_CACHE_DIR/dagger/oci. This directory can be removed whenever you like.
+//
+// Parameters:
+//   - source: File to read the container from.
 func (r *Container) Import(source *File, opts ...ContainerImportOpts) *Container {
 	q := r.q.Select("import")
 	for i := len(opts) - 1; i >= 0; i-- {
@@ -483,6 +597,8 @@ func (r *Container) Import(source *File, opts ...ContainerImportOpts) *Container
 }
 
 // Retrieves the value of the specified label.
+//
+// Nullable; null is returned as the zero value.
 func (r *Container) Label(ctx context.Context, name string) (string, error) {
 	if r.label != nil {
 		return *r.label, nil
@@ -503,8 +619,8 @@ func (r *Container) Labels(ctx context.Context) ([]Label, error) {
 	q = q.Select("name value")
 
 	type labels struct {
-		Name  string
-		Value string
+		Name  string `json:"name"`
+		Value string `json:"value"`
 	}
 
 	convert := func(fields []labels) []Label {
@@ -541,12 +657,19 @@ func (r *Container) Mounts(ctx context.Context) ([]string, error) {
 // ContainerPipelineOpts contains options for Container.Pipeline
 type ContainerPipelineOpts struct {
 	// Pipeline description.
-	Description string
+	//
+	// Optional; the zero value means not set.
+	Description string `json:"description,omitempty"`
 	// Pipeline labels.
-	Labels []PipelineLabel
+	//
+	// Optional; nil means not set.
+	Labels []PipelineLabel `json:"labels,omitempty"`
 }
 
 // Creates a named sub-pipeline
+//
+// Parameters:
+//   - name: Pipeline name.
 func (r *Container) Pipeline(name string, opts ...ContainerPipelineOpts) *Container {
 	q := r.q.Select("pipeline")
 	for i := len(opts) - 1; i >= 0; i-- {
@@ -584,23 +707,33 @@ func (r *Container) Platform(ctx context.Context) (Platform, error) {
 type ContainerPublishOpts struct {
 	// Identifiers for other platform specific containers.
 	// Used for multi-platform image.
-	PlatformVariants []*Container
+	//
+	// Optional; nil means not set.
+	PlatformVariants []*Container `json:"platformVariants,omitempty"`
 	// Force each layer of the published image to use the specified compression algorithm.
 	// If this is unset, then if a layer already has a compressed blob in the engine's
 	// cache, that will be used (this can result in a mix of compression algorithms for
 	// different layers). If this is unset and a layer has no compressed blob in the
 	// engine's cache, then it will be compressed using Gzip.
-	ForcedCompression ImageLayerCompression
+	//
+	// Optional; the zero value means not set.
+	ForcedCompression ImageLayerCompression `json:"forcedCompression,omitempty"`
 	// Use the specified media types for the published image's layers. Defaults to OCI, which
 	// is largely compatible with most recent registries, but Docker may be needed for older
 	// registries without OCI support.
-	MediaTypes ImageMediaTypes
+	//
+	// Optional; the zero value means not set.
+	MediaTypes ImageMediaTypes `json:"mediaTypes,omitempty"`
 }
 
 // Publishes this container as a new image to the specified address.
 //
 // Publish returns a fully qualified ref.
 // It can also publish platform variants.
+//
+// Parameters:
+//   - address: Registry's address to publish the image to.
+//     Formatted as [host]/[user]/[repo]:[tag] (e.g. "docker.io/dagger/dagger:main").
 func (r *Container) Publish(ctx context.Context, address string, opts ...ContainerPublishOpts) (string, error) {
 	if r.publish != nil {
 		return *r.publish, nil
@@ -678,6 +811,8 @@ func (r *Container) Sync(ctx context.Context) (*Container, error) {
 }
 
 // Retrieves the user to be set for all commands.
+//
+// Nullable; null is returned as the zero value.
 func (r *Container) User(ctx context.Context) (string, error) {
 	if r.user != nil {
 		return *r.user, nil
@@ -693,7 +828,9 @@ func (r *Container) User(ctx context.Context) (string, error) {
 // ContainerWithDefaultArgsOpts contains options for Container.WithDefaultArgs
 type ContainerWithDefaultArgsOpts struct {
 	// Arguments to prepend to future executions (e.g., ["-v", "--no-cache"]).
-	Args []string
+	//
+	// Optional; nil means not set.
+	Args []string `json:"args,omitempty"`
 }
 
 // Configures default arguments for future commands.
@@ -715,18 +852,28 @@ func (r *Container) WithDefaultArgs(opts ...ContainerWithDefaultArgsOpts) *Conta
 // ContainerWithDirectoryOpts contains options for Container.WithDirectory
 type ContainerWithDirectoryOpts struct {
 	// Patterns to exclude in the written directory (e.g., ["node_modules/**", ".gitignore", ".git/"]).
-	Exclude []string
+	//
+	// Optional; nil means not set.
+	Exclude []string `json:"exclude,omitempty"`
 	// Patterns to include in the written directory (e.g., ["*.go", "go.mod", "go.sum"]).
-	Include []string
+	//
+	// Optional; nil means not set.
+	Include []string `json:"include,omitempty"`
 	// A user:group to set for the directory and its contents.
 	//
 	// The user and group can either be an ID (1000:1000) or a name (foo:bar).
 	//
 	// If the group is omitted, it defaults to the same as the user.
-	Owner string
+	//
+	// Optional; the zero value means not set.
+	Owner string `json:"owner,omitempty"`
 }
 
 // Retrieves this container plus a directory written at the given path.
+//
+// Parameters:
+//   - path: Location of the written directory (e.g., "/tmp/directory").
+//   - directory: Identifier of the directory to write
 func (r *Container) WithDirectory(path string, directory *Directory, opts ...ContainerWithDirectoryOpts) *Container {
 	q := r.q.Select("withDirectory")
 	for i := len(opts) - 1; i >= 0; i-- {
@@ -753,6 +900,9 @@ func (r *Container) WithDirectory(path string, directory *Directory, opts ...Con
 }
 
 // Retrieves this container but with a different command entrypoint.
+//
+// Parameters:
+//   - args: Entrypoint to use for future executions (e.g., ["go", "run"]).
 func (r *Container) WithEntrypoint(args []string) *Container {
 	q := r.q.Select("withEntrypoint")
 	q = q.Arg("args", args)
@@ -767,10 +917,16 @@ func (r *Container) WithEntrypoint(args []string) *Container {
 type ContainerWithEnvVariableOpts struct {
 	// Replace ${VAR} or $VAR in the value according to the current environment
 	// variables defined in the container (e.g., "/opt/bin:$PATH").
-	Expand bool
+	//
+	// Optional; the zero value means not set.
+	Expand bool `json:"expand,omitempty"`
 }
 
 // Retrieves this container plus the given environment variable.
+//
+// Parameters:
+//   - name: The name of the environment variable (e.g., "HOST").
+//   - value: The value of the environment variable. (e.g., "localhost").
 func (r *Container) WithEnvVariable(name string, value string, opts ...ContainerWithEnvVariableOpts) *Container {
 	q := r.q.Select("withEnvVariable")
 	for i := len(opts) - 1; i >= 0; i-- {
@@ -791,26 +947,42 @@ func (r *Container) WithEnvVariable(name string, value string, opts ...Container
 // ContainerWithExecOpts contains options for Container.WithExec
 type ContainerWithExecOpts struct {
 	// If the container has an entrypoint, ignore it for args rather than using it to wrap them.
-	SkipEntrypoint bool
+	//
+	// Optional; the zero value means not set.
+	SkipEntrypoint bool `json:"skipEntrypoint,omitempty"`
 	// Content to write to the command's standard input before closing (e.g., "Hello world").
-	Stdin string
+	//
+	// Optional; the zero value means not set.
+	Stdin string `json:"stdin,omitempty"`
 	// Redirect the command's standard output to a file in the container (e.g., "/tmp/stdout").
-	RedirectStdout string
+	//
+	// Optional; the zero value means not set.
+	RedirectStdout string `json:"redirectStdout,omitempty"`
 	// Redirect the command's standard error to a file in the container (e.g., "/tmp/stderr").
-	RedirectStderr string
+	//
+	// Optional; the zero value means not set.
+	RedirectStderr string `json:"redirectStderr,omitempty"`
 	// Provides dagger access to the executed command.
 	//
 	// Do not use this option unless you trust the command being executed.
 	// The command being executed WILL BE GRANTED FULL ACCESS TO YOUR HOST FILESYSTEM.
-	ExperimentalPrivilegedNesting bool
+	//
+	// Optional; the zero value means not set.
+	ExperimentalPrivilegedNesting bool `json:"experimentalPrivilegedNesting,omitempty"`
 	// Execute the command with all root capabilities. This is similar to running a command
 	// with "sudo" or executing `docker run` with the `--privileged` flag. Containerization
 	// does not provide any security guarantees when using this option. It should only be used
 	// when absolutely necessary and only with trusted commands.
-	InsecureRootCapabilities bool
+	//
+	// Optional; the zero value means not set.
+	InsecureRootCapabilities bool `json:"insecureRootCapabilities,omitempty"`
 }
 
 // Retrieves this container after executing the specified command inside it.
+//
+// Parameters:
+//   - args: Command to run instead of the container's default command (e.g., ["run", "main.go"]).
+//     If empty, the container's default command is used.
 func (r *Container) WithExec(args []string, opts ...ContainerWithExecOpts) *Container {
 	q := r.q.Select("withExec")
 	for i := len(opts) - 1; i >= 0; i-- {
@@ -850,9 +1022,13 @@ func (r *Container) WithExec(args []string, opts ...ContainerWithExecOpts) *Cont
 // ContainerWithExposedPortOpts contains options for Container.WithExposedPort
 type ContainerWithExposedPortOpts struct {
 	// Transport layer network protocol
-	Protocol NetworkProtocol
+	//
+	// Optional; the zero value means not set.
+	Protocol NetworkProtocol `json:"protocol,omitempty"`
 	// Optional port description
-	Description string
+	//
+	// Optional; the zero value means not set.
+	Description string `json:"description,omitempty"`
 }
 
 // Expose a network port.
@@ -862,6 +1038,9 @@ type ContainerWithExposedPortOpts struct {
 //   - For setting the EXPOSE OCI field when publishing the container
 //
 // Currently experimental; set _EXPERIMENTAL_DAGGER_SERVICES_DNS=0 to disable.
+//
+// Parameters:
+//   - port: Port number to expose
 func (r *Container) WithExposedPort(port int, opts ...ContainerWithExposedPortOpts) *Container {
 	q := r.q.Select("withExposedPort")
 	for i := len(opts) - 1; i >= 0; i-- {
@@ -887,16 +1066,24 @@ type ContainerWithFileOpts struct {
 	// Permission given to the copied file (e.g., 0600).
 	//
 	// Default: 0644.
-	Permissions int
+	//
+	// Optional; the zero value means not set.
+	Permissions int `json:"permissions,omitempty"`
 	// A user:group to set for the file.
 	//
 	// The user and group can either be an ID (1000:1000) or a name (foo:bar).
 	//
 	// If the group is omitted, it defaults to the same as the user.
-	Owner string
+	//
+	// Optional; the zero value means not set.
+	Owner string `json:"owner,omitempty"`
 }
 
 // Retrieves this container plus the contents of the given file copied to the given path.
+//
+// Parameters:
+//   - path: Location of the copied file (e.g., "/tmp/file.txt").
+//   - source: Identifier of the file to copy.
 func (r *Container) WithFile(path string, source *File, opts ...ContainerWithFileOpts) *Container {
 	q := r.q.Select("withFile")
 	for i := len(opts) - 1; i >= 0; i-- {
@@ -930,6 +1117,10 @@ func (r *Container) WithFocus() *Container {
 }
 
 // Retrieves this container plus the given label.
+//
+// Parameters:
+//   - name: The name of the label (e.g., "org.opencontainers.artifact.created").
+//   - value: The value of the label (e.g., "2023-01-01T00:00:00Z").
 func (r *Container) WithLabel(name string, value string) *Container {
 	q := r.q.Select("withLabel")
 	q = q.Arg("name", name)
@@ -944,9 +1135,13 @@ func (r *Container) WithLabel(name string, value string) *Container {
 // ContainerWithMountedCacheOpts contains options for Container.WithMountedCache
 type ContainerWithMountedCacheOpts struct {
 	// Identifier of the directory to use as the cache volume's root.
-	Source *Directory
+	//
+	// Optional; nil means not set.
+	Source *Directory `json:"source,omitempty"`
 	// Sharing mode of the cache volume.
-	Sharing CacheSharingMode
+	//
+	// Optional; the zero value means not set.
+	Sharing CacheSharingMode `json:"sharing,omitempty"`
 	// A user:group to set for the mounted cache directory.
 	//
 	// Note that this changes the ownership of the specified mount along with the
@@ -956,10 +1151,16 @@ type ContainerWithMountedCacheOpts struct {
 	// The user and group can either be an ID (1000:1000) or a name (foo:bar).
 	//
 	// If the group is omitted, it defaults to the same as the user.
-	Owner string
+	//
+	// Optional; the zero value means not set.
+	Owner string `json:"owner,omitempty"`
 }
 
 // Retrieves this container plus a cache volume mounted at the given path.
+//
+// Parameters:
+//   - path: Location of the cache directory (e.g., "/cache/node_modules").
+//   - cache: Identifier of the cache volume to mount.
 func (r *Container) WithMountedCache(path string, cache *CacheVolume, opts ...ContainerWithMountedCacheOpts) *Container {
 	q := r.q.Select("withMountedCache")
 	for i := len(opts) - 1; i >= 0; i-- {
@@ -992,10 +1193,16 @@ type ContainerWithMountedDirectoryOpts struct {
 	// The user and group can either be an ID (1000:1000) or a name (foo:bar).
 	//
 	// If the group is omitted, it defaults to the same as the user.
-	Owner string
+	//
+	// Optional; the zero value means not set.
+	Owner string `json:"owner,omitempty"`
 }
 
 // Retrieves this container plus a directory mounted at the given path.
+//
+// Parameters:
+//   - path: Location of the mounted directory (e.g., "/mnt/directory").
+//   - source: Identifier of the mounted directory.
 func (r *Container) WithMountedDirectory(path string, source *Directory, opts ...ContainerWithMountedDirectoryOpts) *Container {
 	q := r.q.Select("withMountedDirectory")
 	for i := len(opts) - 1; i >= 0; i-- {
@@ -1020,10 +1227,16 @@ type ContainerWithMountedFileOpts struct {
 	// The user and group can either be an ID (1000:1000) or a name (foo:bar).
 	//
 	// If the group is omitted, it defaults to the same as the user.
-	Owner string
+	//
+	// Optional; the zero value means not set.
+	Owner string `json:"owner,omitempty"`
 }
 
 // Retrieves this container plus a file mounted at the given path.
+//
+// Parameters:
+//   - path: Location of the mounted file (e.g., "/tmp/file.txt").
+//   - source: Identifier of the mounted file.
 func (r *Container) WithMountedFile(path string, source *File, opts ...ContainerWithMountedFileOpts) *Container {
 	q := r.q.Select("withMountedFile")
 	for i := len(opts) - 1; i >= 0; i-- {
@@ -1048,10 +1261,16 @@ type ContainerWithMountedSecretOpts struct {
 	// The user and group can either be an ID (1000:1000) or a name (foo:bar).
 	//
 	// If the group is omitted, it defaults to the same as the user.
-	Owner string
+	//
+	// Optional; the zero value means not set.
+	Owner string `json:"owner,omitempty"`
 }
 
 // Retrieves this container plus a secret mounted into a file at the given path.
+//
+// Parameters:
+//   - path: Location of the secret file (e.g., "/tmp/secret.txt").
+//   - source: Identifier of the secret to mount.
 func (r *Container) WithMountedSecret(path string, source *Secret, opts ...ContainerWithMountedSecretOpts) *Container {
 	q := r.q.Select("withMountedSecret")
 	for i := len(opts) - 1; i >= 0; i-- {
@@ -1070,6 +1289,9 @@ func (r *Container) WithMountedSecret(path string, source *Secret, opts ...Conta
 }
 
 // Retrieves this container plus a temporary directory mounted at the given path.
+//
+// Parameters:
+//   - path: Location of the temporary directory (e.g., "/tmp/temp_dir").
 func (r *Container) WithMountedTemp(path string) *Container {
 	q := r.q.Select("withMountedTemp")
 	q = q.Arg("path", path)
@@ -1083,20 +1305,29 @@ func (r *Container) WithMountedTemp(path string) *Container {
 // ContainerWithNewFileOpts contains options for Container.WithNewFile
 type ContainerWithNewFileOpts struct {
 	// Content of the file to write (e.g., "Hello world!").
-	Contents string
+	//
+	// Optional; the zero value means not set.
+	Contents string `json:"contents,omitempty"`
 	// Permission given to the written file (e.g., 0600).
 	//
 	// Default: 0644.
-	Permissions int
+	//
+	// Optional; the zero value means not set.
+	Permissions int `json:"permissions,omitempty"`
 	// A user:group to set for the file.
 	//
 	// The user and group can either be an ID (1000:1000) or a name (foo:bar).
 	//
 	// If the group is omitted, it defaults to the same as the user.
-	Owner string
+	//
+	// Optional; the zero value means not set.
+	Owner string `json:"owner,omitempty"`
 }
 
 // Retrieves this container plus a new file written at the given path.
+//
+// Parameters:
+//   - path: Location of the written file (e.g., "/tmp/file.txt").
 func (r *Container) WithNewFile(path string, opts ...ContainerWithNewFileOpts) *Container {
 	q := r.q.Select("withNewFile")
 	for i := len(opts) - 1; i >= 0; i-- {
@@ -1122,6 +1353,12 @@ func (r *Container) WithNewFile(path string, opts ...ContainerWithNewFileOpts) *
 }
 
 // Retrieves this container with a registry authentication for a given address.
+//
+// Parameters:
+//   - address: Registry's address to bind the authentication to.
+//     Formatted as [host]/[user]/[repo]:[tag] (e.g. docker.io/dagger/dagger:main).
+//   - username: The username of the registry's account (e.g., "Dagger").
+//   - secret: The API key, password or token to authenticate to this registry.
 func (r *Container) WithRegistryAuth(address string, username string, secret *Secret) *Container {
 	q := r.q.Select("withRegistryAuth")
 	q = q.Arg("address", address)
@@ -1146,6 +1383,10 @@ func (r *Container) WithRootfs(directory *Directory) *Container {
 }
 
 // Retrieves this container plus an env variable containing the given secret.
+//
+// Parameters:
+//   - name: The name of the secret variable (e.g., "API_SECRET").
+//   - secret: The identifier of the secret value.
 func (r *Container) WithSecretVariable(name string, secret *Secret) *Container {
 	q := r.q.Select("withSecretVariable")
 	q = q.Arg("name", name)
@@ -1167,6 +1408,10 @@ func (r *Container) WithSecretVariable(name string, secret *Secret) *Container {
 // The service dependency will also convey to any files or directories produced by the container.
 //
 // Currently experimental; set _EXPERIMENTAL_DAGGER_SERVICES_DNS=0 to disable.
+//
+// Parameters:
+//   - alias: A name that can be used to reach the service from the container
+//   - service: Identifier of the service container
 func (r *Container) WithServiceBinding(alias string, service *Container) *Container {
 	q := r.q.Select("withServiceBinding")
 	q = q.Arg("alias", alias)
@@ -1185,10 +1430,16 @@ type ContainerWithUnixSocketOpts struct {
 	// The user and group can either be an ID (1000:1000) or a name (foo:bar).
 	//
 	// If the group is omitted, it defaults to the same as the user.
-	Owner string
+	//
+	// Optional; the zero value means not set.
+	Owner string `json:"owner,omitempty"`
 }
 
 // Retrieves this container plus a socket forwarded to the given Unix socket path.
+//
+// Parameters:
+//   - path: Location of the forwarded Unix socket (e.g., "/tmp/socket").
+//   - source: Identifier of the socket to forward.
 func (r *Container) WithUnixSocket(path string, source *Socket, opts ...ContainerWithUnixSocketOpts) *Container {
 	q := r.q.Select("withUnixSocket")
 	for i := len(opts) - 1; i >= 0; i-- {
@@ -1207,6 +1458,9 @@ func (r *Container) WithUnixSocket(path string, source *Socket, opts ...Containe
 }
 
 // Retrieves this container with a different command user.
+//
+// Parameters:
+//   - name: The user to set (e.g., "root").
 func (r *Container) WithUser(name string) *Container {
 	q := r.q.Select("withUser")
 	q = q.Arg("name", name)
@@ -1218,6 +1472,9 @@ func (r *Container) WithUser(name string) *Container {
 }
 
 // Retrieves this container with a different working directory.
+//
+// Parameters:
+//   - path: The path to set as the working directory (e.g., "/app").
 func (r *Container) WithWorkdir(path string) *Container {
 	q := r.q.Select("withWorkdir")
 	q = q.Arg("path", path)
@@ -1229,6 +1486,9 @@ func (r *Container) WithWorkdir(path string) *Container {
 }
 
 // Retrieves this container minus the given environment variable.
+//
+// Parameters:
+//   - name: The name of the environment variable (e.g., "HOST").
 func (r *Container) WithoutEnvVariable(name string) *Container {
 	q := r.q.Select("withoutEnvVariable")
 	q = q.Arg("name", name)
@@ -1242,12 +1502,17 @@ func (r *Container) WithoutEnvVariable(name string) *Container {
 // ContainerWithoutExposedPortOpts contains options for Container.WithoutExposedPort
 type ContainerWithoutExposedPortOpts struct {
 	// Port protocol to unexpose
-	Protocol NetworkProtocol
+	//
+	// Optional; the zero value means not set.
+	Protocol NetworkProtocol `json:"protocol,omitempty"`
 }
 
 // Unexpose a previously exposed port.
 //
 // Currently experimental; set _EXPERIMENTAL_DAGGER_SERVICES_DNS=0 to disable.
+//
+// Parameters:
+//   - port: Port number to unexpose
 func (r *Container) WithoutExposedPort(port int, opts ...ContainerWithoutExposedPortOpts) *Container {
 	q := r.q.Select("withoutExposedPort")
 	for i := len(opts) - 1; i >= 0; i-- {
@@ -1278,6 +1543,9 @@ func (r *Container) WithoutFocus() *Container {
 }
 
 // Retrieves this container minus the given environment label.
+//
+// Parameters:
+//   - name: The name of the label to remove (e.g., "org.opencontainers.artifact.created").
 func (r *Container) WithoutLabel(name string) *Container {
 	q := r.q.Select("withoutLabel")
 	q = q.Arg("name", name)
@@ -1289,6 +1557,9 @@ func (r *Container) WithoutLabel(name string) *Container {
 }
 
 // Retrieves this container after unmounting everything at the given path.
+//
+// Parameters:
+//   - path: Location of the cache directory (e.g., "/cache/node_modules").
 func (r *Container) WithoutMount(path string) *Container {
 	q := r.q.Select("withoutMount")
 	q = q.Arg("path", path)
@@ -1300,6 +1571,10 @@ func (r *Container) WithoutMount(path string) *Container {
 }
 
 // Retrieves this container without the registry authentication of a given address.
+//
+// Parameters:
+//   - address: Registry's address to remove the authentication from.
+//     Formatted as [host]/[user]/[repo]:[tag] (e.g. docker.io/dagger/dagger:main).
 func (r *Container) WithoutRegistryAuth(address string) *Container {
 	q := r.q.Select("withoutRegistryAuth")
 	q = q.Arg("address", address)
@@ -1311,6 +1586,9 @@ func (r *Container) WithoutRegistryAuth(address string) *Container {
 }
 
 // Retrieves this container with a previously added Unix socket removed.
+//
+// Parameters:
+//   - path: Location of the socket to remove (e.g., "/tmp/socket").
 func (r *Container) WithoutUnixSocket(path string) *Container {
 	q := r.q.Select("withoutUnixSocket")
 	q = q.Arg("path", path)
@@ -1322,6 +1600,8 @@ func (r *Container) WithoutUnixSocket(path string) *Container {
 }
 
 // Retrieves the working directory for all commands.
+//
+// Nullable; null is returned as the zero value.
 func (r *Container) Workdir(ctx context.Context) (string, error) {
 	if r.workdir != nil {
 		return *r.workdir, nil
@@ -1353,6 +1633,9 @@ func (r *Directory) With(f WithDirectoryFunc) *Directory {
 }
 
 // Gets the difference between this directory and an another directory.
+//
+// Parameters:
+//   - other: Identifier of the directory to compare.
 func (r *Directory) Diff(other *Directory) *Directory {
 	q := r.q.Select("diff")
 	q = q.Arg("other", other)
@@ -1364,6 +1647,9 @@ func (r *Directory) Diff(other *Directory) *Directory {
 }
 
 // Retrieves a directory at the given path.
+//
+// Parameters:
+//   - path: Location of the directory to retrieve (e.g., "/src").
 func (r *Directory) Directory(path string) *Directory {
 	q := r.q.Select("directory")
 	q = q.Arg("path", path)
@@ -1379,17 +1665,27 @@ type DirectoryDockerBuildOpts struct {
 	// Path to the Dockerfile to use (e.g., "frontend.Dockerfile").
 	//
 	// Defaults: './Dockerfile'.
-	Dockerfile string
+	//
+	// Optional; the zero value means not set.
+	Dockerfile string `json:"dockerfile,omitempty"`
 	// The platform to build.
-	Platform Platform
+	//
+	// Optional; the zero value means not set.
+	Platform Platform `json:"platform,omitempty"`
 	// Build arguments to use in the build.
-	BuildArgs []BuildArg
+	//
+	// Optional; nil means not set.
+	BuildArgs []BuildArg `json:"buildArgs,omitempty"`
 	// Target build stage to build.
-	Target string
+	//
+	// Optional; the zero value means not set.
+	Target string `json:"target,omitempty"`
 	// Secrets to pass to the build.
 	//
 	// They will be mounted at /run/secrets/[secret-name].
-	Secrets []*Secret
+	//
+	// Optional; nil means not set.
+	Secrets []*Secret `json:"secrets,omitempty"`
 }
 
 // Builds a new Docker container from this directory.
@@ -1427,7 +1723,9 @@ func (r *Directory) DockerBuild(opts ...DirectoryDockerBuildOpts) *Container {
 // DirectoryEntriesOpts contains options for Directory.Entries
 type DirectoryEntriesOpts struct {
 	// Location of the directory to look at (e.g., "/src").
-	Path string
+	//
+	// Optional; the zero value means not set.
+	Path string `json:"path,omitempty"`
 }
 
 // Returns a list of files and directories at the given path.
@@ -1447,6 +1745,9 @@ func (r *Directory) Entries(ctx context.Context, opts ...DirectoryEntriesOpts) (
 }
 
 // Writes the contents of the directory to a path on the host.
+//
+// Parameters:
+//   - path: Location of the copied directory (e.g., "logs/").
 func (r *Directory) Export(ctx context.Context, path string) (bool, error) {
 	if r.export != nil {
 		return *r.export, nil
@@ -1461,6 +1762,9 @@ func (r *Directory) Export(ctx context.Context, path string) (bool, error) {
 }
 
 // Retrieves a file at the given path.
+//
+// Parameters:
+//   - path: Location of the file to retrieve (e.g., "README.md").
 func (r *Directory) File(path string) *File {
 	q := r.q.Select("file")
 	q = q.Arg("path", path)
@@ -1503,15 +1807,43 @@ func (r *Directory) XXX_GraphQLID(ctx context.Context) (string, error) {
 	return string(id), nil
 }
 
+// MarshalJSON encodes a Directory as its ID. The ID is only known without
+// querying it for a Directory decoded from JSON or returned in a list:
+// marshal the result of ID otherwise.
+func (r *Directory) MarshalJSON() ([]byte, error) {
+	if r.id == nil {
+		return nil, errors.New("the ID of the Directory isn't known, marshal the result of ID instead")
+	}
+	return json.Marshal(*r.id)
+}
+
+// UnmarshalJSON decodes a Directory from its ID. Without a client, the
+// Directory can only be passed as an argument or have its ID retrieved.
+func (r *Directory) UnmarshalJSON(data []byte) error {
+	var id DirectoryID
+	if err := json.Unmarshal(data, &id); err != nil {
+		return err
+	}
+	*r = Directory{id: &id}
+	return nil
+}
+
 // DirectoryPipelineOpts contains options for Directory.Pipeline
 type DirectoryPipelineOpts struct {
 	// Pipeline description.
-	Description string
+	//
+	// Optional; the zero value means not set.
+	Description string `json:"description,omitempty"`
 	// Pipeline labels.
-	Labels []PipelineLabel
+	//
+	// Optional; nil means not set.
+	Labels []PipelineLabel `json:"labels,omitempty"`
 }
 
 // Creates a named sub-pipeline
+//
+// Parameters:
+//   - name: Pipeline name.
 func (r *Directory) Pipeline(name string, opts ...DirectoryPipelineOpts) *Directory {
 	q := r.q.Select("pipeline")
 	for i := len(opts) - 1; i >= 0; i-- {
@@ -1542,12 +1874,20 @@ func (r *Directory) Sync(ctx context.Context) (*Directory, error) {
 // DirectoryWithDirectoryOpts contains options for Directory.WithDirectory
 type DirectoryWithDirectoryOpts struct {
 	// Exclude artifacts that match the given pattern (e.g., ["node_modules/", ".git*"]).
-	Exclude []string
+	//
+	// Optional; nil means not set.
+	Exclude []string `json:"exclude,omitempty"`
 	// Include only artifacts that match the given pattern (e.g., ["app/", "package.*"]).
-	Include []string
+	//
+	// Optional; nil means not set.
+	Include []string `json:"include,omitempty"`
 }
 
 // Retrieves this directory plus a directory written at the given path.
+//
+// Parameters:
+//   - path: Location of the written directory (e.g., "/src/").
+//   - directory: Identifier of the directory to copy.
 func (r *Directory) WithDirectory(path string, directory *Directory, opts ...DirectoryWithDirectoryOpts) *Directory {
 	q := r.q.Select("withDirectory")
 	for i := len(opts) - 1; i >= 0; i-- {
@@ -1574,10 +1914,16 @@ type DirectoryWithFileOpts struct {
 	// Permission given to the copied file (e.g., 0600).
 	//
 	// Default: 0644.
-	Permissions int
+	//
+	// Optional; the zero value means not set.
+	Permissions int `json:"permissions,omitempty"`
 }
 
 // Retrieves this directory plus the contents of the given file copied to the given path.
+//
+// Parameters:
+//   - path: Location of the copied file (e.g., "/file.txt").
+//   - source: Identifier of the file to copy.
 func (r *Directory) WithFile(path string, source *File, opts ...DirectoryWithFileOpts) *Directory {
 	q := r.q.Select("withFile")
 	for i := len(opts) - 1; i >= 0; i-- {
@@ -1600,10 +1946,15 @@ type DirectoryWithNewDirectoryOpts struct {
 	// Permission granted to the created directory (e.g., 0777).
 	//
 	// Default: 0755.
-	Permissions int
+	//
+	// Optional; the zero value means not set.
+	Permissions int `json:"permissions,omitempty"`
 }
 
 // Retrieves this directory plus a new directory created at the given path.
+//
+// Parameters:
+//   - path: Location of the directory created (e.g., "/logs").
 func (r *Directory) WithNewDirectory(path string, opts ...DirectoryWithNewDirectoryOpts) *Directory {
 	q := r.q.Select("withNewDirectory")
 	for i := len(opts) - 1; i >= 0; i-- {
@@ -1625,10 +1976,16 @@ type DirectoryWithNewFileOpts struct {
 	// Permission given to the copied file (e.g., 0600).
 	//
 	// Default: 0644.
-	Permissions int
+	//
+	// Optional; the zero value means not set.
+	Permissions int `json:"permissions,omitempty"`
 }
 
 // Retrieves this directory plus a new file written at the given path.
+//
+// Parameters:
+//   - path: Location of the written file (e.g., "/file.txt").
+//   - contents: Content of the written file (e.g., "Hello world!").
 func (r *Directory) WithNewFile(path string, contents string, opts ...DirectoryWithNewFileOpts) *Directory {
 	q := r.q.Select("withNewFile")
 	for i := len(opts) - 1; i >= 0; i-- {
@@ -1647,6 +2004,10 @@ func (r *Directory) WithNewFile(path string, contents string, opts ...DirectoryW
 }
 
 // Retrieves this directory with all file/dir timestamps set to the given time.
+//
+// Parameters:
+//   - timestamp: Timestamp to set dir/files in.
+//     Formatted in seconds following Unix epoch (e.g., 1672531199).
 func (r *Directory) WithTimestamps(timestamp int) *Directory {
 	q := r.q.Select("withTimestamps")
 	q = q.Arg("timestamp", timestamp)
@@ -1658,6 +2019,9 @@ func (r *Directory) WithTimestamps(timestamp int) *Directory {
 }
 
 // Retrieves this directory with the directory at the given path removed.
+//
+// Parameters:
+//   - path: Location of the directory to remove (e.g., ".github/").
 func (r *Directory) WithoutDirectory(path string) *Directory {
 	q := r.q.Select("withoutDirectory")
 	q = q.Arg("path", path)
@@ -1669,6 +2033,9 @@ func (r *Directory) WithoutDirectory(path string) *Directory {
 }
 
 // Retrieves this directory with the file at the given path removed.
+//
+// Parameters:
+//   - path: Location of the file to remove (e.g., "/file.txt").
 func (r *Directory) WithoutFile(path string) *Directory {
 	q := r.q.Select("withoutFile")
 	q = q.Arg("path", path)
@@ -1751,10 +2118,15 @@ func (r *File) Contents(ctx context.Context) (string, error) {
 type FileExportOpts struct {
 	// If allowParentDirPath is true, the path argument can be a directory path, in which case
 	// the file will be created in that directory.
-	AllowParentDirPath bool
+	//
+	// Optional; the zero value means not set.
+	AllowParentDirPath bool `json:"allowParentDirPath,omitempty"`
 }
 
 // Writes the file to a file path on the host.
+//
+// Parameters:
+//   - path: Location of the written directory (e.g., "output.txt").
 func (r *File) Export(ctx context.Context, path string, opts ...FileExportOpts) (bool, error) {
 	if r.export != nil {
 		return *r.export, nil
@@ -1806,6 +2178,27 @@ func (r *File) XXX_GraphQLID(ctx context.Context) (string, error) {
 	return string(id), nil
 }
 
+// MarshalJSON encodes a File as its ID. The ID is only known without
+// querying it for a File decoded from JSON or returned in a list:
+// marshal the result of ID otherwise.
+func (r *File) MarshalJSON() ([]byte, error) {
+	if r.id == nil {
+		return nil, errors.New("the ID of the File isn't known, marshal the result of ID instead")
+	}
+	return json.Marshal(*r.id)
+}
+
+// UnmarshalJSON decodes a File from its ID. Without a client, the
+// File can only be passed as an argument or have its ID retrieved.
+func (r *File) UnmarshalJSON(data []byte) error {
+	var id FileID
+	if err := json.Unmarshal(data, &id); err != nil {
+		return err
+	}
+	*r = File{id: &id}
+	return nil
+}
+
 // Gets the size of the file, in bytes.
 func (r *File) Size(ctx context.Context) (int, error) {
 	if r.size != nil {
@@ -1827,6 +2220,10 @@ func (r *File) Sync(ctx context.Context) (*File, error) {
 }
 
 // Retrieves this file with its created/modified timestamps set to the given time.
+//
+// Parameters:
+//   - timestamp: Timestamp to set dir/files in.
+//     Formatted in seconds following Unix epoch (e.g., 1672531199).
 func (r *File) WithTimestamps(timestamp int) *File {
 	q := r.q.Select("withTimestamps")
 	q = q.Arg("timestamp", timestamp)
@@ -1845,9 +2242,10 @@ type GitRef struct {
 
 // GitRefTreeOpts contains options for GitRef.Tree
 type GitRefTreeOpts struct {
-	SSHKnownHosts string
-
-	SSHAuthSocket *Socket
+	// Optional; the zero value means not set.
+	SSHKnownHosts string `json:"sshKnownHosts,omitempty"`
+	// Optional; nil means not set.
+	SSHAuthSocket *Socket `json:"sshAuthSocket,omitempty"`
 }
 
 // The filesystem tree at this ref.
@@ -1877,6 +2275,9 @@ type GitRepository struct {
 }
 
 // Returns details on one branch.
+//
+// Parameters:
+//   - name: Branch's name (e.g., "main").
 func (r *GitRepository) Branch(name string) *GitRef {
 	q := r.q.Select("branch")
 	q = q.Arg("name", name)
@@ -1888,6 +2289,9 @@ func (r *GitRepository) Branch(name string) *GitRef {
 }
 
 // Returns details on one commit.
+//
+// Parameters:
+//   - id: Identifier of the commit (e.g., "b6315d8f2810962c601af73f86831f6866ea798b").
 func (r *GitRepository) Commit(id string) *GitRef {
 	q := r.q.Select("commit")
 	q = q.Arg("id", id)
@@ -1899,6 +2303,9 @@ func (r *GitRepository) Commit(id string) *GitRef {
 }
 
 // Returns details on one tag.
+//
+// Parameters:
+//   - name: Tag's name (e.g., "v0.3.9").
 func (r *GitRepository) Tag(name string) *GitRef {
 	q := r.q.Select("tag")
 	q = q.Arg("name", name)
@@ -1918,12 +2325,19 @@ type Host struct {
 // HostDirectoryOpts contains options for Host.Directory
 type HostDirectoryOpts struct {
 	// Exclude artifacts that match the given pattern (e.g., ["node_modules/", ".git*"]).
-	Exclude []string
+	//
+	// Optional; nil means not set.
+	Exclude []string `json:"exclude,omitempty"`
 	// Include only artifacts that match the given pattern (e.g., ["app/", "package.*"]).
-	Include []string
+	//
+	// Optional; nil means not set.
+	Include []string `json:"include,omitempty"`
 }
 
 // Accesses a directory on the host.
+//
+// Parameters:
+//   - path: Location of the directory to access (e.g., ".").
 func (r *Host) Directory(path string, opts ...HostDirectoryOpts) *Directory {
 	q := r.q.Select("directory")
 	for i := len(opts) - 1; i >= 0; i-- {
@@ -1945,6 +2359,9 @@ func (r *Host) Directory(path string, opts ...HostDirectoryOpts) *Directory {
 }
 
 // Accesses a file on the host.
+//
+// Parameters:
+//   - path: Location of the file to retrieve (e.g., "README.md").
 func (r *Host) File(path string) *File {
 	q := r.q.Select("file")
 	q = q.Arg("path", path)
@@ -1957,6 +2374,10 @@ func (r *Host) File(path string) *File {
 
 // Sets a secret given a user-defined name and the file path on the host, and returns the secret.
 // The file is limited to a size of 512000 bytes.
+//
+// Parameters:
+//   - name: The user defined name for this secret.
+//   - path: Location of the file to set as a secret.
 func (r *Host) SetSecretFile(name string, path string) *Secret {
 	q := r.q.Select("setSecretFile")
 	q = q.Arg("name", name)
@@ -1969,6 +2390,9 @@ func (r *Host) SetSecretFile(name string, path string) *Secret {
 }
 
 // Accesses a Unix socket on the host.
+//
+// Parameters:
+//   - path: Location of the Unix socket (e.g., "/var/run/docker.sock").
 func (r *Host) UnixSocket(path string) *Socket {
 	q := r.q.Select("unixSocket")
 	q = q.Arg("path", path)
@@ -2025,6 +2449,8 @@ type Port struct {
 }
 
 // The port description.
+//
+// Nullable; null is returned as the zero value.
 func (r *Port) Description(ctx context.Context) (string, error) {
 	if r.description != nil {
 		return *r.description, nil
@@ -2081,19 +2507,24 @@ func (r *Project) With(f WithProjectFunc) *Project {
 }
 
 // Commands provided by this project
+//
+// Nullable; nil means null.
 func (r *Project) Commands(ctx context.Context) ([]ProjectCommand, error) {
 	q := r.q.Select("commands")
 
 	q = q.Select("description id name resultType")
 
 	type commands struct {
-		Description string
-		Id          ProjectCommandID
-		Name        string
-		ResultType  string
+		Description string           `json:"description,omitempty"`
+		Id          ProjectCommandID `json:"id"`
+		Name        string           `json:"name"`
+		ResultType  string           `json:"resultType,omitempty"`
 	}
 
 	convert := func(fields []commands) []ProjectCommand {
+		if fields == nil {
+			return nil
+		}
 		out := []ProjectCommand{}
 
 		for i := range fields {
@@ -2146,6 +2577,27 @@ func (r *Project) XXX_GraphQLID(ctx context.Context) (string, error) {
 	return string(id), nil
 }
 
+// MarshalJSON encodes a Project as its ID. The ID is only known without
+// querying it for a Project decoded from JSON or returned in a list:
+// marshal the result of ID otherwise.
+func (r *Project) MarshalJSON() ([]byte, error) {
+	if r.id == nil {
+		return nil, errors.New("the ID of the Project isn't known, marshal the result of ID instead")
+	}
+	return json.Marshal(*r.id)
+}
+
+// UnmarshalJSON decodes a Project from its ID. Without a client, the
+// Project can only be passed as an argument or have its ID retrieved.
+func (r *Project) UnmarshalJSON(data []byte) error {
+	var id ProjectID
+	if err := json.Unmarshal(data, &id); err != nil {
+		return err
+	}
+	*r = Project{id: &id}
+	return nil
+}
+
 // Initialize this project from the given directory and config path
 func (r *Project) Load(source *Directory, configPath string) *Project {
 	q := r.q.Select("load")
@@ -2183,6 +2635,8 @@ type ProjectCommand struct {
 }
 
 // Documentation for what this command does.
+//
+// Nullable; null is returned as the zero value.
 func (r *ProjectCommand) Description(ctx context.Context) (string, error) {
 	if r.description != nil {
 		return *r.description, nil
@@ -2196,17 +2650,22 @@ func (r *ProjectCommand) Description(ctx context.Context) (string, error) {
 }
 
 // Flags accepted by this command.
+//
+// Nullable; nil means null.
 func (r *ProjectCommand) Flags(ctx context.Context) ([]ProjectCommandFlag, error) {
 	q := r.q.Select("flags")
 
 	q = q.Select("description name")
 
 	type flags struct {
-		Description string
-		Name        string
+		Description string `json:"description,omitempty"`
+		Name        string `json:"name"`
 	}
 
 	convert := func(fields []flags) []ProjectCommandFlag {
+		if fields == nil {
+			return nil
+		}
 		out := []ProjectCommandFlag{}
 
 		for i := range fields {
@@ -2259,6 +2718,27 @@ func (r *ProjectCommand) XXX_GraphQLID(ctx context.Context) (string, error) {
 	return string(id), nil
 }
 
+// MarshalJSON encodes a ProjectCommand as its ID. The ID is only known without
+// querying it for a ProjectCommand decoded from JSON or returned in a list:
+// marshal the result of ID otherwise.
+func (r *ProjectCommand) MarshalJSON() ([]byte, error) {
+	if r.id == nil {
+		return nil, errors.New("the ID of the ProjectCommand isn't known, marshal the result of ID instead")
+	}
+	return json.Marshal(*r.id)
+}
+
+// UnmarshalJSON decodes a ProjectCommand from its ID. Without a client, the
+// ProjectCommand can only be passed as an argument or have its ID retrieved.
+func (r *ProjectCommand) UnmarshalJSON(data []byte) error {
+	var id ProjectCommandID
+	if err := json.Unmarshal(data, &id); err != nil {
+		return err
+	}
+	*r = ProjectCommand{id: &id}
+	return nil
+}
+
 // The name of the command.
 func (r *ProjectCommand) Name(ctx context.Context) (string, error) {
 	if r.name != nil {
@@ -2273,6 +2753,8 @@ func (r *ProjectCommand) Name(ctx context.Context) (string, error) {
 }
 
 // The name of the type returned by this command.
+//
+// Nullable; null is returned as the zero value.
 func (r *ProjectCommand) ResultType(ctx context.Context) (string, error) {
 	if r.resultType != nil {
 		return *r.resultType, nil
@@ -2286,19 +2768,24 @@ func (r *ProjectCommand) ResultType(ctx context.Context) (string, error) {
 }
 
 // Subcommands, if any, that this command provides.
+//
+// Nullable; nil means null.
 func (r *ProjectCommand) Subcommands(ctx context.Context) ([]ProjectCommand, error) {
 	q := r.q.Select("subcommands")
 
 	q = q.Select("description id name resultType")
 
 	type subcommands struct {
-		Description string
-		Id          ProjectCommandID
-		Name        string
-		ResultType  string
+		Description string           `json:"description,omitempty"`
+		Id          ProjectCommandID `json:"id"`
+		Name        string           `json:"name"`
+		ResultType  string           `json:"resultType,omitempty"`
 	}
 
 	convert := func(fields []subcommands) []ProjectCommand {
+		if fields == nil {
+			return nil
+		}
 		out := []ProjectCommand{}
 
 		for i := range fields {
@@ -2329,6 +2816,8 @@ type ProjectCommandFlag struct {
 }
 
 // Documentation for what this flag sets.
+//
+// Nullable; null is returned as the zero value.
 func (r *ProjectCommandFlag) Description(ctx context.Context) (string, error) {
 	if r.description != nil {
 		return *r.description, nil
@@ -2364,6 +2853,9 @@ func (r *Client) With(f WithClientFunc) *Client {
 }
 
 // Constructs a cache volume for a given cache key.
+//
+// Parameters:
+//   - key: A string identifier to target this cache volume (e.g., "modules-cache").
 func (r *Client) CacheVolume(key string) *CacheVolume {
 	q := r.q.Select("cacheVolume")
 	q = q.Arg("key", key)
@@ -2375,6 +2867,9 @@ func (r *Client) CacheVolume(key string) *CacheVolume {
 }
 
 // Checks if the current Dagger Engine is compatible with an SDK's required version.
+//
+// Parameters:
+//   - version: The SDK's required version.
 func (r *Client) CheckVersionCompatibility(ctx context.Context, version string) (bool, error) {
 	q := r.q.Select("checkVersionCompatibility")
 	q = q.Arg("version", version)
@@ -2387,9 +2882,10 @@ func (r *Client) CheckVersionCompatibility(ctx context.Context, version string)
 
 // ContainerOpts contains options for Client.Container
 type ContainerOpts struct {
-	ID ContainerID
-
-	Platform Platform
+	// Optional; the zero value means not set.
+	ID ContainerID `json:"id,omitempty"`
+	// Optional; the zero value means not set.
+	Platform Platform `json:"platform,omitempty"`
 }
 
 // Loads a container from ID.
@@ -2428,7 +2924,8 @@ func (r *Client) DefaultPlatform(ctx context.Context) (Platform, error) {
 
 // DirectoryOpts contains options for Client.Directory
 type DirectoryOpts struct {
-	ID DirectoryID
+	// Optional; the zero value means not set.
+	ID DirectoryID `json:"id,omitempty"`
 }
 
 // Load a directory by ID. No argument produces an empty directory.
@@ -2461,12 +2958,21 @@ func (r *Client) File(id FileID) *File {
 // GitOpts contains options for Client.Git
 type GitOpts struct {
 	// Set to true to keep .git directory.
-	KeepGitDir bool
+	//
+	// Optional; the zero value means not set.
+	KeepGitDir bool `json:"keepGitDir,omitempty"`
 	// A service which must be started before the repo is fetched.
-	ExperimentalServiceHost *Container
+	//
+	// Optional; nil means not set.
+	ExperimentalServiceHost *Container `json:"experimentalServiceHost,omitempty"`
 }
 
 // Queries a git repository.
+//
+// Parameters:
+//   - url: Url of the git repository.
+//     Can be formatted as https://{host}/{owner}/{repo}, git@{host}/{owner}/{repo}
+//     Suffix ".git" is optional.
 func (r *Client) Git(url string, opts ...GitOpts) *GitRepository {
 	q := r.q.Select("git")
 	for i := len(opts) - 1; i >= 0; i-- {
@@ -2500,10 +3006,15 @@ func (r *Client) Host() *Host {
 // HTTPOpts contains options for Client.HTTP
 type HTTPOpts struct {
 	// A service which must be started before the URL is fetched.
-	ExperimentalServiceHost *Container
+	//
+	// Optional; nil means not set.
+	ExperimentalServiceHost *Container `json:"experimentalServiceHost,omitempty"`
 }
 
 // Returns a file containing an http remote url content.
+//
+// Parameters:
+//   - url: HTTP url to get the content from (e.g., "https://docs.dagger.io").
 func (r *Client) HTTP(url string, opts ...HTTPOpts) *File {
 	q := r.q.Select("http")
 	for i := len(opts) - 1; i >= 0; i-- {
@@ -2523,12 +3034,19 @@ func (r *Client) HTTP(url string, opts ...HTTPOpts) *File {
 // PipelineOpts contains options for Client.Pipeline
 type PipelineOpts struct {
 	// Pipeline description.
-	Description string
+	//
+	// Optional; the zero value means not set.
+	Description string `json:"description,omitempty"`
 	// Pipeline labels.
-	Labels []PipelineLabel
+	//
+	// Optional; nil means not set.
+	Labels []PipelineLabel `json:"labels,omitempty"`
 }
 
 // Creates a named sub-pipeline.
+//
+// Parameters:
+//   - name: Pipeline name.
 func (r *Client) Pipeline(name string, opts ...PipelineOpts) *Client {
 	q := r.q.Select("pipeline")
 	for i := len(opts) - 1; i >= 0; i-- {
@@ -2551,7 +3069,8 @@ func (r *Client) Pipeline(name string, opts ...PipelineOpts) *Client {
 
 // ProjectOpts contains options for Client.Project
 type ProjectOpts struct {
-	ID ProjectID
+	// Optional; the zero value means not set.
+	ID ProjectID `json:"id,omitempty"`
 }
 
 // Load a project from ID.
@@ -2572,7 +3091,8 @@ func (r *Client) Project(opts ...ProjectOpts) *Project {
 
 // ProjectCommandOpts contains options for Client.ProjectCommand
 type ProjectCommandOpts struct {
-	ID ProjectCommandID
+	// Optional; the zero value means not set.
+	ID ProjectCommandID `json:"id,omitempty"`
 }
 
 // Load a project command from ID.
@@ -2604,6 +3124,10 @@ func (r *Client) Secret(id SecretID) *Secret {
 
 // Sets a secret given a user defined name to its plaintext and returns the secret.
 // The plaintext value is limited to a size of 128000 bytes.
+//
+// Parameters:
+//   - name: The user defined name for this secret
+//   - plaintext: The plaintext of the secret
 func (r *Client) SetSecret(name string, plaintext string) *Secret {
 	q := r.q.Select("setSecret")
 	q = q.Arg("name", name)
@@ -2617,7 +3141,8 @@ func (r *Client) SetSecret(name string, plaintext string) *Secret {
 
 // SocketOpts contains options for Client.Socket
 type SocketOpts struct {
-	ID SocketID
+	// Optional; the zero value means not set.
+	ID SocketID `json:"id,omitempty"`
 }
 
 // Loads a socket by its ID.
@@ -2677,6 +3202,27 @@ func (r *Secret) XXX_GraphQLID(ctx context.Context) (string, error) {
 	return string(id), nil
 }
 
+// MarshalJSON encodes a Secret as its ID. The ID is only known without
+// querying it for a Secret decoded from JSON or returned in a list:
+// marshal the result of ID otherwise.
+func (r *Secret) MarshalJSON() ([]byte, error) {
+	if r.id == nil {
+		return nil, errors.New("the ID of the Secret isn't known, marshal the result of ID instead")
+	}
+	return json.Marshal(*r.id)
+}
+
+// UnmarshalJSON decodes a Secret from its ID. Without a client, the
+// Secret can only be passed as an argument or have its ID retrieved.
+func (r *Secret) UnmarshalJSON(data []byte) error {
+	var id SecretID
+	if err := json.Unmarshal(data, &id); err != nil {
+		return err
+	}
+	*r = Secret{id: &id}
+	return nil
+}
+
 // The value of this secret.
 func (r *Secret) Plaintext(ctx context.Context) (string, error) {
 	if r.plaintext != nil {
@@ -2729,14 +3275,64 @@ func (r *Socket) XXX_GraphQLID(ctx context.Context) (string, error) {
 	return string(id), nil
 }
 
+// MarshalJSON encodes a Socket as its ID. The ID is only known without
+// querying it for a Socket decoded from JSON or returned in a list:
+// marshal the result of ID otherwise.
+func (r *Socket) MarshalJSON() ([]byte, error) {
+	if r.id == nil {
+		return nil, errors.New("the ID of the Socket isn't known, marshal the result of ID instead")
+	}
+	return json.Marshal(*r.id)
+}
+
+// UnmarshalJSON decodes a Socket from its ID. Without a client, the
+// Socket can only be passed as an argument or have its ID retrieved.
+func (r *Socket) UnmarshalJSON(data []byte) error {
+	var id SocketID
+	if err := json.Unmarshal(data, &id); err != nil {
+		return err
+	}
+	*r = Socket{id: &id}
+	return nil
+}
+
+// Sharing mode of the cache volume.
 type CacheSharingMode string
 
 const (
-	Locked  CacheSharingMode = "LOCKED"
+	// Shares the cache volume amongst many build pipelines,
+	// but will serialize the writes
+	Locked CacheSharingMode = "LOCKED"
+	// Keeps a cache volume for a single build pipeline
 	Private CacheSharingMode = "PRIVATE"
-	Shared  CacheSharingMode = "SHARED"
+	// Shares the cache volume amongst many build pipelines
+	Shared CacheSharingMode = "SHARED"
 )
 
+// AllCacheSharingMode lists the values of the CacheSharingMode enum, in schema order.
+var AllCacheSharingMode = []CacheSharingMode{
+	Locked,
+	Private,
+	Shared,
+}
+
+// String returns the GraphQL value of the CacheSharingMode.
+func (e CacheSharingMode) String() string {
+	return string(e)
+}
+
+// ParseCacheSharingMode parses a GraphQL value of the CacheSharingMode enum.
+// It returns an error if the value is unknown.
+func ParseCacheSharingMode(s string) (CacheSharingMode, error) {
+	switch v := CacheSharingMode(s); v {
+	case Locked, Private, Shared:
+		return v, nil
+	default:
+		return "", fmt.Errorf("unknown CacheSharingMode value %q", s)
+	}
+}
+
+// Compression algorithm to use for image layers.
 type ImageLayerCompression string
 
 const (
@@ -2746,6 +3342,31 @@ const (
 	Zstd         ImageLayerCompression = "Zstd"
 )
 
+// AllImageLayerCompression lists the values of the ImageLayerCompression enum, in schema order.
+var AllImageLayerCompression = []ImageLayerCompression{
+	Estargz,
+	Gzip,
+	Uncompressed,
+	Zstd,
+}
+
+// String returns the GraphQL value of the ImageLayerCompression.
+func (e ImageLayerCompression) String() string {
+	return string(e)
+}
+
+// ParseImageLayerCompression parses a GraphQL value of the ImageLayerCompression enum.
+// It returns an error if the value is unknown.
+func ParseImageLayerCompression(s string) (ImageLayerCompression, error) {
+	switch v := ImageLayerCompression(s); v {
+	case Estargz, Gzip, Uncompressed, Zstd:
+		return v, nil
+	default:
+		return "", fmt.Errorf("unknown ImageLayerCompression value %q", s)
+	}
+}
+
+// Mediatypes to use in published or exported image metadata.
 type ImageMediaTypes string
 
 const (
@@ -2753,9 +3374,56 @@ const (
 	Ocimediatypes    ImageMediaTypes = "OCIMediaTypes"
 )
 
+// AllImageMediaTypes lists the values of the ImageMediaTypes enum, in schema order.
+var AllImageMediaTypes = []ImageMediaTypes{
+	Dockermediatypes,
+	Ocimediatypes,
+}
+
+// String returns the GraphQL value of the ImageMediaTypes.
+func (e ImageMediaTypes) String() string {
+	return string(e)
+}
+
+// ParseImageMediaTypes parses a GraphQL value of the ImageMediaTypes enum.
+// It returns an error if the value is unknown.
+func ParseImageMediaTypes(s string) (ImageMediaTypes, error) {
+	switch v := ImageMediaTypes(s); v {
+	case Dockermediatypes, Ocimediatypes:
+		return v, nil
+	default:
+		return "", fmt.Errorf("unknown ImageMediaTypes value %q", s)
+	}
+}
+
+// Transport layer network protocol associated to a port.
 type NetworkProtocol string
 
 const (
+	// TCP (Transmission Control Protocol)
 	Tcp NetworkProtocol = "TCP"
+	// UDP (User Datagram Protocol)
 	Udp NetworkProtocol = "UDP"
 )
+
+// AllNetworkProtocol lists the values of the NetworkProtocol enum, in schema order.
+var AllNetworkProtocol = []NetworkProtocol{
+	Tcp,
+	Udp,
+}
+
+// String returns the GraphQL value of the NetworkProtocol.
+func (e NetworkProtocol) String() string {
+	return string(e)
+}
+
+// ParseNetworkProtocol parses a GraphQL value of the NetworkProtocol enum.
+// It returns an error if the value is unknown.
+func ParseNetworkProtocol(s string) (NetworkProtocol, error) {
+	switch v := NetworkProtocol(s); v {
+	case Tcp, Udp:
+		return v, nil
+	default:
+		return "", fmt.Errorf("unknown NetworkProtocol value %q", s)
+	}
+}
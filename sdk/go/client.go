@@ -72,7 +72,7 @@ func Connect(ctx context.Context, opts ...ClientOpt) (_ *Client, rerr error) {
 	if err != nil {
 		return nil, err
 	}
-	gql := errorWrappedClient{graphql.NewClient("http://"+conn.Host()+"/query", conn)}
+	gql := errorWrappedClient{querybuilder.NewClient("http://"+conn.Host()+"/query", conn)}
 
 	c := &Client{
 		c:    gql,
@@ -150,11 +150,18 @@ type Response struct {
 }
 
 type errorWrappedClient struct {
-	graphql.Client
+	querybuilder.MultipartClient
 }
 
 func (c errorWrappedClient) MakeRequest(ctx context.Context, req *graphql.Request, resp *graphql.Response) error {
-	err := c.Client.MakeRequest(ctx, req, resp)
+	return wrapError(c.MultipartClient.MakeRequest(ctx, req, resp))
+}
+
+func (c errorWrappedClient) MakeMultipartRequest(ctx context.Context, req *graphql.Request, uploads []querybuilder.Upload, resp *graphql.Response) error {
+	return wrapError(c.MultipartClient.MakeMultipartRequest(ctx, req, uploads, resp))
+}
+
+func wrapError(err error) error {
 	if err != nil {
 		// return custom error without wrapping to enable casting
 		if e := getCustomError(err); e != nil {
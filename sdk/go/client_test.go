@@ -2,11 +2,17 @@ package dagger
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	"dagger.io/dagger/internal/querybuilder"
 )
 
 func TestDirectory(t *testing.T) {
@@ -226,6 +232,62 @@ func TestList(t *testing.T) {
 	require.Equal(t, "BAZ", envValue)
 }
 
+func TestObjectJSON(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	id := ContainerID("sha256:abc")
+	data, err := json.Marshal(struct {
+		Container *Container `json:"container"`
+	}{&Container{id: &id}})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"container": "sha256:abc"}`, string(data))
+
+	var v struct {
+		Container *Container `json:"container"`
+	}
+	require.NoError(t, json.Unmarshal(data, &v))
+	decoded, err := v.Container.ID(ctx)
+	require.NoError(t, err)
+	require.Equal(t, id, decoded)
+
+	// Decoded objects have no client to query their other fields with.
+	_, err = v.Container.WithEnvVariable("FOO", "bar").Stdout(ctx)
+	require.ErrorIs(t, err, querybuilder.ErrNoClient)
+
+	// The ID isn't queried while marshalling.
+	_, err = json.Marshal(&Container{})
+	require.ErrorContains(t, err, "the ID of the Container isn't known")
+}
+
+func TestMultipartError(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, _, err := r.FormFile("0"); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"errors": [{"message": "exit code: 1", "extensions": {"_type": "EXEC_ERROR", "exitCode": 1}}]}`))
+	}))
+	defer srv.Close()
+
+	// Uploads are sent by the client of Connect, and their errors are
+	// reported the same way.
+	gql := errorWrappedClient{querybuilder.NewClient(srv.URL, srv.Client())}
+	var response string
+	err := querybuilder.Query().
+		Select("upload").Multipart().
+		UploadArg("file", "Upload", strings.NewReader("hello")).
+		Bind(&response).
+		Execute(ctx, gql)
+
+	var exErr *ExecError
+	require.ErrorAs(t, err, &exErr)
+	require.Equal(t, 1, exErr.ExitCode)
+}
+
 func TestExecError(t *testing.T) {
 	t.Parallel()
 	ctx := context.Background()
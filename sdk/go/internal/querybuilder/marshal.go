@@ -3,7 +3,9 @@ package querybuilder
 import (
 	"bytes"
 	"context"
+	"encoding"
 	"fmt"
+	"math/big"
 	"reflect"
 	"strings"
 
@@ -21,6 +23,12 @@ type GraphQLMarshaller interface {
 	XXX_GraphQLID(ctx context.Context) (string, error)
 }
 
+// graphQLScalar is implemented by generated scalars whose Go type name
+// differs from their GraphQL name.
+type graphQLScalar interface {
+	XXX_GraphQLType() string
+}
+
 const (
 	GraphQLMarshallerType   = "XXX_GraphQLType"
 	GraphQLMarshallerIDType = "XXX_GraphQLIDType"
@@ -49,6 +57,14 @@ func init() {
 	gqlMarshaller = reflect.TypeOf((*GraphQLMarshaller)(nil)).Elem()
 }
 
+func asGraphQLScalar(v reflect.Value) (graphQLScalar, bool) {
+	if !v.CanInterface() {
+		return nil, false
+	}
+	s, ok := v.Interface().(graphQLScalar)
+	return s, ok
+}
+
 func MarshalGQL(ctx context.Context, v any) (string, error) {
 	return marshalValue(ctx, reflect.ValueOf(v))
 }
@@ -60,26 +76,54 @@ func marshalValue(ctx context.Context, v reflect.Value) (string, error) {
 		return marshalCustom(ctx, v)
 	}
 
+	// Scalars mapped to Go types (e.g. time.Time) are sent as strings
+	if (t.Kind() == reflect.Pointer || t.Kind() == reflect.Interface) && v.IsNil() {
+		return "null", nil
+	}
+	if t.Kind() == reflect.Interface {
+		return marshalValue(ctx, v.Elem())
+	}
+	// Files to upload are sent as variables bound to the multipart request
+	if uploadScalar(ctx) != "" && isUpload(v) {
+		return marshalUpload(ctx, v)
+	}
+	if v.CanInterface() {
+		switch value := v.Interface().(type) {
+		case *big.Rat:
+			s, err := ratString(value)
+			if err != nil {
+				return "", err
+			}
+			return marshalString(s), nil
+		case encoding.TextMarshaler:
+			text, err := value.MarshalText()
+			if err != nil {
+				return "", err
+			}
+			return marshalString(string(text)), nil
+		}
+	}
+
 	switch t.Kind() {
 	case reflect.Bool:
 		return fmt.Sprintf("%t", v.Bool()), nil
-	case reflect.Int:
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 		return fmt.Sprintf("%d", v.Int()), nil
 	case reflect.String:
 		name := t.Name()
-		// escape strings following graphQL spec
-		// https://github.com/graphql/graphql-spec/blob/main/spec/Section%202%20--%20Language.md#string-value
-		var buf bytes.Buffer
-		gqlgen.MarshalString(v.String()).MarshalGQL(&buf)
 
 		// distinguish enum const values and customScalars from string type
 		// GraphQL complains if you try to put a string literal in place of an enum: FOO vs "FOO"
 		// Enums do not follow the unicode escape
-		_, found := customScalar[t.Name()]
+		// Scalars generated with a prefix or suffix report their GraphQL name
+		if s, ok := asGraphQLScalar(v); ok {
+			name = s.XXX_GraphQLType()
+		}
+		_, found := customScalar[name]
 		if name != "string" && !found {
 			return fmt.Sprintf("%s", v.String()), nil //nolint:gosimple,staticcheck
 		}
-		return buf.String(), nil //nolint:gosimple,staticcheck
+		return marshalString(v.String()), nil
 	case reflect.Pointer:
 		if v.IsNil() {
 			return "null", nil
@@ -113,11 +157,19 @@ func marshalValue(ctx context.Context, v reflect.Value) (string, error) {
 			eg.Go(func() error {
 				f := t.Field(i)
 				name := f.Name
-				tag := strings.SplitN(f.Tag.Get("json"), ",", 2)[0]
-				if tag != "" {
-					name = tag
+				tag := strings.Split(f.Tag.Get("json"), ",")
+				if tag[0] != "" {
+					name = tag[0]
+				}
+				// Leave unset optional fields out instead of sending null
+				if contains(tag[1:], "omitempty") && IsZeroValue(v.Field(i).Interface()) {
+					return nil
+				}
+				fctx := gctx
+				if tag := f.Tag.Get("graphql"); strings.HasPrefix(tag, uploadTagPrefix) {
+					fctx = withUploadValue(gctx, strings.TrimPrefix(tag, uploadTagPrefix))
 				}
-				m, err := marshalValue(gctx, v.Field(i))
+				m, err := marshalValue(fctx, v.Field(i))
 				if err != nil {
 					return err
 				}
@@ -128,12 +180,56 @@ func marshalValue(ctx context.Context, v reflect.Value) (string, error) {
 		if err := eg.Wait(); err != nil {
 			return "", err
 		}
-		return fmt.Sprintf("{%s}", strings.Join(elems, ",")), nil
+		fields := elems[:0]
+		for _, elem := range elems {
+			if elem != "" {
+				fields = append(fields, elem)
+			}
+		}
+		return fmt.Sprintf("{%s}", strings.Join(fields, ",")), nil
 	default:
 		panic(fmt.Errorf("unsupported argument of kind %s", t.Kind()))
 	}
 }
 
+// marshalString escapes strings following graphQL spec
+// https://github.com/graphql/graphql-spec/blob/main/spec/Section%202%20--%20Language.md#string-value
+func marshalString(s string) string {
+	var buf bytes.Buffer
+	gqlgen.MarshalString(s).MarshalGQL(&buf)
+	return buf.String()
+}
+
+// ratString formats a rational as an exact decimal (e.g. `1.25` rather
+// than `5/4`). Rationals without a finite decimal expansion (e.g. `1/3`)
+// can't be sent exactly, so they're rejected rather than rounded.
+func ratString(r *big.Rat) (string, error) {
+	if r.IsInt() {
+		return r.Num().String(), nil
+	}
+	// The expansion is finite if the denominator only has 2 and 5 as
+	// prime factors, with as many digits as the largest of their powers.
+	denom := new(big.Int).Set(r.Denom())
+	twos := int(denom.TrailingZeroBits())
+	denom.Rsh(denom, uint(twos))
+	fives := 0
+	five := big.NewInt(5)
+	for q, m := new(big.Int), new(big.Int); ; fives++ {
+		if q.QuoRem(denom, five, m); m.Sign() != 0 {
+			break
+		}
+		denom.Set(q)
+	}
+	prec := twos
+	if fives > prec {
+		prec = fives
+	}
+	if denom.Cmp(big.NewInt(1)) != 0 {
+		return "", fmt.Errorf("%s has no finite decimal expansion", r.RatString())
+	}
+	return r.FloatString(prec), nil
+}
+
 func marshalCustom(ctx context.Context, v reflect.Value) (string, error) {
 	result := v.MethodByName(GraphQLMarshallerID).Call([]reflect.Value{
 		reflect.ValueOf(ctx),
@@ -150,6 +246,9 @@ func marshalCustom(ctx context.Context, v reflect.Value) (string, error) {
 }
 
 func IsZeroValue(value any) bool {
+	if value == nil {
+		return true
+	}
 	v := reflect.ValueOf(value)
 	kind := v.Type().Kind()
 	switch kind {
@@ -161,3 +260,12 @@ func IsZeroValue(value any) bool {
 		return v.IsZero()
 	}
 }
+
+func contains(s []string, v string) bool {
+	for _, i := range s {
+		if i == v {
+			return true
+		}
+	}
+	return false
+}
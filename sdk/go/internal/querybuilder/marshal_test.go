@@ -3,20 +3,27 @@ package querybuilder
 import (
 	"context"
 	"fmt"
+	"math/big"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
 
 type customStringType string
 
+type prefixedContainerID string
+
+func (prefixedContainerID) XXX_GraphQLType() string { return "ContainerID" }
+
 func TestMarshalGQL(t *testing.T) {
 	var (
 		str         = "hello world"
 		unicode     = "∆?–∂∂√˛viÙ˜Ÿ¿GÆÓ∂Ó˘◊ñ" //nolint:stylecheck
 		strNullPtr  *string
-		strPtrSlice                  = []*string{&str}
-		customStr   customStringType = "test"
+		strPtrSlice                     = []*string{&str}
+		customStr   customStringType    = "test"
+		prefixedID  prefixedContainerID = "id"
 	)
 
 	testCases := []struct {
@@ -39,6 +46,10 @@ func TestMarshalGQL(t *testing.T) {
 			v:      42,
 			expect: "42",
 		},
+		{
+			v:      int64(1) << 40,
+			expect: "1099511627776",
+		},
 		{
 			v:      true,
 			expect: "true",
@@ -72,6 +83,10 @@ func TestMarshalGQL(t *testing.T) {
 			v:      customStr,
 			expect: "test",
 		},
+		{
+			v:      prefixedID,
+			expect: "\"id\"",
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -81,6 +96,36 @@ func TestMarshalGQL(t *testing.T) {
 	}
 }
 
+func TestMarshalGQLMappedScalars(t *testing.T) {
+	var nilRat *big.Rat
+	testCases := []struct {
+		v      any
+		expect string
+	}{
+		{v: big.NewRat(5, 4), expect: `"1.25"`},
+		{v: big.NewRat(-1, 8), expect: `"-0.125"`},
+		{v: big.NewRat(3, 50), expect: `"0.06"`},
+		{v: big.NewRat(42, 1), expect: `"42"`},
+		{v: nilRat, expect: "null"},
+		{v: time.Date(2023, 7, 14, 10, 0, 0, 0, time.UTC), expect: `"2023-07-14T10:00:00Z"`},
+		{v: []*big.Rat{big.NewRat(1, 2)}, expect: `["0.5"]`},
+	}
+
+	for _, testCase := range testCases {
+		enc, err := MarshalGQL(context.TODO(), testCase.v)
+		require.NoError(t, err)
+		require.Equal(t, testCase.expect, enc)
+	}
+}
+
+func TestMarshalGQLInexactRat(t *testing.T) {
+	_, err := MarshalGQL(context.TODO(), big.NewRat(1, 3))
+	require.ErrorContains(t, err, "1/3 has no finite decimal expansion")
+
+	_, err = MarshalGQL(context.TODO(), []*big.Rat{big.NewRat(1, 2), big.NewRat(2, 7)})
+	require.ErrorContains(t, err, "2/7 has no finite decimal expansion")
+}
+
 func TestMarshalGQLStruct(t *testing.T) {
 	s := struct {
 		A   string `json:"a,omitempty"`
@@ -98,6 +143,21 @@ func TestMarshalGQLStruct(t *testing.T) {
 	require.Equal(t, `{a:"test",b:42,sub:{x:["1"]}}`, enc)
 }
 
+func TestMarshalGQLStructOmitEmpty(t *testing.T) {
+	name := "test"
+	s := struct {
+		Name  *string  `json:"name,omitempty"`
+		Value *string  `json:"value,omitempty"`
+		Tags  []string `json:"tags,omitempty"`
+		Count int      `json:"count"`
+	}{
+		Name: &name,
+	}
+	enc, err := MarshalGQL(context.TODO(), s)
+	require.NoError(t, err)
+	require.Equal(t, `{name:"test",count:0}`, enc)
+}
+
 type customMarshaller struct {
 	v     string
 	count int
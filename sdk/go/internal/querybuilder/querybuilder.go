@@ -3,9 +3,11 @@ package querybuilder
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/Khan/genqlient/graphql"
 	"golang.org/x/sync/errgroup"
@@ -21,6 +23,13 @@ type Selection struct {
 	args  map[string]*argument
 	bind  interface{}
 
+	// fragment is set for inline fragments, which select fields on a
+	// specific type without nesting the response.
+	fragment bool
+
+	// multipart is set for operations that may upload files.
+	multipart bool
+
 	prev *Selection
 }
 
@@ -46,6 +55,16 @@ func (s *Selection) Select(name string) *Selection {
 	return s.SelectWithAlias("", name)
 }
 
+// InlineFragment selects the following fields only if the current selection
+// resolves to the given type (e.g. a member of a union).
+func (s *Selection) InlineFragment(typeName string) *Selection {
+	return &Selection{
+		name:     "... on " + typeName,
+		prev:     s,
+		fragment: true,
+	}
+}
+
 func (s *Selection) Arg(name string, value any) *Selection {
 	sel := *s
 	if sel.args == nil {
@@ -65,12 +84,13 @@ func (s *Selection) Bind(v interface{}) *Selection {
 }
 
 func (s *Selection) marshalArguments(ctx context.Context) error {
+	multipart := s.isMultipart()
 	eg, gctx := errgroup.WithContext(ctx)
 	for _, sel := range s.path() {
 		for _, arg := range sel.args {
 			arg := arg
 			eg.Go(func() error {
-				return arg.marshal(gctx)
+				return arg.marshal(gctx, multipart)
 			})
 		}
 	}
@@ -86,6 +106,17 @@ func (s *Selection) build(ctx context.Context) (string, error) {
 	var b strings.Builder
 	b.WriteString("query")
 
+	if uploads := s.uploads(); len(uploads) > 0 {
+		b.WriteRune('(')
+		for i, u := range uploads {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString("$" + u.Variable + ":" + u.Type + "!")
+		}
+		b.WriteRune(')')
+	}
+
 	path := s.path()
 
 	for _, sel := range path {
@@ -120,6 +151,10 @@ func (s *Selection) build(ctx context.Context) (string, error) {
 
 func (s *Selection) unpack(data interface{}) error {
 	for _, i := range s.path() {
+		if i.fragment {
+			continue
+		}
+
 		k := i.name
 		if i.alias != "" {
 			k = i.alias
@@ -140,26 +175,49 @@ func (s *Selection) unpack(data interface{}) error {
 			if err != nil {
 				return err
 			}
-			json.Unmarshal(marshalled, s.bind)
+			if err := json.Unmarshal(marshalled, s.bind); err != nil {
+				return err
+			}
 		}
 	}
 
 	return nil
 }
 
+// ErrNoClient is returned when executing a query without a client, e.g. for
+// objects decoded from their ID in JSON.
+var ErrNoClient = errors.New("no client to execute the query: objects decoded from JSON can only be passed as arguments or have their ID retrieved")
+
 func (s *Selection) Execute(ctx context.Context, c graphql.Client) error {
+	if c == nil {
+		return ErrNoClient
+	}
 	query, err := s.build(ctx)
 	if err != nil {
 		return err
 	}
 
 	var response any
-	err = c.MakeRequest(ctx,
-		&graphql.Request{
-			Query: query,
-		},
-		&graphql.Response{Data: &response},
-	)
+	req := &graphql.Request{
+		Query: query,
+	}
+	if uploads := s.uploads(); len(uploads) > 0 {
+		mc, ok := c.(MultipartClient)
+		if !ok {
+			return fmt.Errorf("uploading files requires a multipart request, which the GraphQL client of type %T doesn't support", c)
+		}
+		if err := s.rewindUploads(); err != nil {
+			return err
+		}
+		variables := map[string]any{}
+		for _, u := range uploads {
+			variables[u.Variable] = nil
+		}
+		req.Variables = variables
+		err = mc.MakeMultipartRequest(ctx, req, uploads, &graphql.Response{Data: &response})
+	} else {
+		err = c.MakeRequest(ctx, req, &graphql.Response{Data: &response})
+	}
 	if err != nil {
 		return err
 	}
@@ -170,13 +228,28 @@ func (s *Selection) Execute(ctx context.Context, c graphql.Client) error {
 type argument struct {
 	value      any
 	marshalled string
+	uploads    []Upload
 	once       sync.Once
+
+	// upload is the scalar of the upload arguments, see UploadArg.
+	upload string
+	// sent is set once the uploads have been sent by an execution.
+	sent atomic.Bool
 }
 
-func (a *argument) marshal(ctx context.Context) error {
+func (a *argument) marshal(ctx context.Context, multipart bool) error {
 	var err error
 	a.once.Do(func() {
-		a.marshalled, err = MarshalGQL(ctx, a.value)
+		if a.upload != "" {
+			ctx = withUploadValue(ctx, a.upload)
+		}
+		if !multipart {
+			a.marshalled, err = MarshalGQL(ctx, a.value)
+			return
+		}
+		collector := &uploadCollector{}
+		a.marshalled, err = MarshalGQL(context.WithValue(ctx, uploadsKey{}, collector), a.value)
+		a.uploads = collector.uploads
 	})
 	return err
 }
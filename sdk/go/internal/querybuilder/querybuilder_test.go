@@ -30,6 +30,24 @@ func TestAlias(t *testing.T) {
 	require.Equal(t, `query{core{image(ref:"alpine"){foo:file(path:"/etc/alpine-release")}}}`, q)
 }
 
+func TestInlineFragment(t *testing.T) {
+	var name string
+	root := Query().
+		Select("search").
+		InlineFragment("Folder").
+		Select("name").Bind(&name)
+
+	q, err := root.build(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, `query{search{... on Folder{name}}}`, q)
+
+	var response any
+	err = json.Unmarshal([]byte(`{"search": {"name": "TEST"}}`), &response)
+	require.NoError(t, err)
+	require.NoError(t, root.unpack(response))
+	require.Equal(t, "TEST", name)
+}
+
 func TestArgsCollision(t *testing.T) {
 	q, err := Query().
 		Select("a").Arg("arg", "one").
@@ -125,3 +143,15 @@ func TestUnpack(t *testing.T) {
 	require.NoError(t, root.unpack(response))
 	require.Equal(t, "TEST", contents)
 }
+
+func TestUnpackError(t *testing.T) {
+	var count int
+	root := Query().
+		Select("foo").
+		Select("count").Bind(&count)
+
+	var response any
+	err := json.Unmarshal([]byte(`{"foo": {"count": "many"}}`), &response)
+	require.NoError(t, err)
+	require.Error(t, root.unpack(response))
+}
@@ -0,0 +1,297 @@
+package querybuilder
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/Khan/genqlient/graphql"
+)
+
+// Upload is a file sent along a multipart request for an argument of the
+// `Upload` scalar, bound to a variable of the query.
+type Upload struct {
+	// Variable is the name of the query variable, without `$`.
+	Variable string
+	// Type is the GraphQL scalar of the variable, e.g. `Upload`.
+	Type string
+	// File is either an io.Reader or a *multipart.FileHeader.
+	File any
+}
+
+// MultipartClient is implemented by the GraphQL clients able to send
+// multipart requests, which are required to upload files (see
+// WriteMultipart).
+type MultipartClient interface {
+	graphql.Client
+
+	MakeMultipartRequest(ctx context.Context, req *graphql.Request, uploads []Upload, resp *graphql.Response) error
+}
+
+// Multipart flags the selection as an operation that may upload files:
+// the io.Reader and *multipart.FileHeader values of its upload arguments
+// (see UploadArg) and input object fields (tagged with
+// `graphql:"upload=<scalar>"`) are sent as files of a multipart request
+// instead of being inlined in the query.
+func (s *Selection) Multipart() *Selection {
+	sel := *s
+	sel.multipart = true
+	return &sel
+}
+
+// UploadArg sets an argument of an upload scalar (e.g. `Upload`), or a list
+// of them, whose files are sent along the multipart request (see
+// Multipart).
+func (s *Selection) UploadArg(name, scalar string, value any) *Selection {
+	sel := s.Arg(name, value)
+	sel.args[name].upload = scalar
+	return sel
+}
+
+func (s *Selection) isMultipart() bool {
+	for _, sel := range s.path() {
+		if sel.multipart {
+			return true
+		}
+	}
+	return false
+}
+
+// uploads returns the files of the marshalled arguments, sorted by
+// variable.
+func (s *Selection) uploads() []Upload {
+	var uploads []Upload
+	for _, sel := range s.path() {
+		for _, arg := range sel.args {
+			uploads = append(uploads, arg.uploads...)
+		}
+	}
+	sort.Slice(uploads, func(i, j int) bool {
+		return uploads[i].Variable < uploads[j].Variable
+	})
+	return uploads
+}
+
+// rewindUploads seeks back to their start the files already sent by a
+// previous execution of the selection, which are sent again.
+func (s *Selection) rewindUploads() error {
+	for _, sel := range s.path() {
+		for _, arg := range sel.args {
+			if err := arg.rewindUploads(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (a *argument) rewindUploads() error {
+	if !a.sent.Swap(true) {
+		return nil
+	}
+	for _, u := range a.uploads {
+		switch f := u.File.(type) {
+		case *multipart.FileHeader:
+			// opened again for each request
+		case io.Seeker:
+			if _, err := f.Seek(0, io.SeekStart); err != nil {
+				return fmt.Errorf("upload %s: %w", u.Variable, err)
+			}
+		default:
+			return fmt.Errorf("upload %s: %w", u.Variable, ErrUploadSent)
+		}
+	}
+	return nil
+}
+
+// WriteMultipart encodes a request and its uploads following the GraphQL
+// multipart request specification, then closes the writer.
+// https://github.com/jaydenseric/graphql-multipart-request-spec
+func WriteMultipart(w *multipart.Writer, req *graphql.Request, uploads []Upload) error {
+	operations, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	if err := w.WriteField("operations", string(operations)); err != nil {
+		return err
+	}
+
+	files := map[string][]string{}
+	for i, u := range uploads {
+		files[strconv.Itoa(i)] = []string{"variables." + u.Variable}
+	}
+	mapping, err := json.Marshal(files)
+	if err != nil {
+		return err
+	}
+	if err := w.WriteField("map", string(mapping)); err != nil {
+		return err
+	}
+
+	for i, u := range uploads {
+		if err := writeUpload(w, strconv.Itoa(i), u.File); err != nil {
+			return fmt.Errorf("upload %s: %w", u.Variable, err)
+		}
+	}
+	return w.Close()
+}
+
+// NewClient returns a GraphQL client sending its queries to an endpoint,
+// along with their uploads as multipart requests (see WriteMultipart).
+func NewClient(endpoint string, httpClient graphql.Doer) MultipartClient {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &multipartClient{
+		Client:     graphql.NewClient(endpoint, httpClient),
+		endpoint:   endpoint,
+		httpClient: httpClient,
+	}
+}
+
+type multipartClient struct {
+	graphql.Client
+
+	endpoint   string
+	httpClient graphql.Doer
+}
+
+func (c *multipartClient) MakeMultipartRequest(ctx context.Context, req *graphql.Request, uploads []Upload, resp *graphql.Response) error {
+	// stream the files instead of buffering them
+	body, pw := io.Pipe()
+	w := multipart.NewWriter(pw)
+	go func() {
+		pw.CloseWithError(WriteMultipart(w, req, uploads))
+	}()
+	defer body.Close()
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, body)
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", w.FormDataContentType())
+
+	httpResp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode != http.StatusOK {
+		respBody, err := io.ReadAll(httpResp.Body)
+		if err != nil {
+			respBody = []byte(fmt.Sprintf("<unreadable: %v>", err))
+		}
+		return fmt.Errorf("returned error %v: %s", httpResp.Status, respBody)
+	}
+
+	if err := json.NewDecoder(httpResp.Body).Decode(resp); err != nil {
+		return err
+	}
+	if len(resp.Errors) > 0 {
+		return resp.Errors
+	}
+	return nil
+}
+
+func writeUpload(w *multipart.Writer, field string, file any) error {
+	var (
+		name = field
+		r    io.Reader
+	)
+	switch f := file.(type) {
+	case *multipart.FileHeader:
+		opened, err := f.Open()
+		if err != nil {
+			return err
+		}
+		defer opened.Close()
+		name, r = f.Filename, opened
+	case io.Reader:
+		if named, ok := f.(interface{ Name() string }); ok {
+			name = filepath.Base(named.Name())
+		}
+		r = f
+	default:
+		return fmt.Errorf("unsupported file of type %T", file)
+	}
+
+	part, err := w.CreateFormFile(field, name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(part, r)
+	return err
+}
+
+var (
+	readerType     = reflect.TypeOf((*io.Reader)(nil)).Elem()
+	fileHeaderType = reflect.TypeOf(&multipart.FileHeader{})
+
+	errNotMultipart = errors.New("files can only be uploaded by the arguments of a multipart selection")
+
+	// ErrUploadSent is returned when executing a selection again while one
+	// of its files, read by the previous execution, can't be read again.
+	ErrUploadSent = errors.New("the file was already sent by a previous execution and doesn't implement io.Seeker to be sent again")
+
+	// lastUpload numbers the variables of the uploads, which are marshalled
+	// once per argument.
+	lastUpload atomic.Int64
+)
+
+// uploadTagPrefix prefixes the `graphql` struct tag of the input object
+// fields of an upload scalar, followed by the scalar.
+// Example: `graphql:"upload=Upload"`
+const uploadTagPrefix = "upload="
+
+type uploadsKey struct{}
+
+// uploadValueKey holds the upload scalar of the values being marshalled,
+// which are the only ones sent as files.
+type uploadValueKey struct{}
+
+func withUploadValue(ctx context.Context, scalar string) context.Context {
+	return context.WithValue(ctx, uploadValueKey{}, scalar)
+}
+
+func uploadScalar(ctx context.Context) string {
+	scalar, _ := ctx.Value(uploadValueKey{}).(string)
+	return scalar
+}
+
+// uploadCollector gathers the files of an argument marshalled as variables.
+type uploadCollector struct {
+	mu      sync.Mutex
+	uploads []Upload
+}
+
+func isUpload(v reflect.Value) bool {
+	t := v.Type()
+	return v.CanInterface() && (t == fileHeaderType || t.Implements(readerType))
+}
+
+func marshalUpload(ctx context.Context, v reflect.Value) (string, error) {
+	collector, ok := ctx.Value(uploadsKey{}).(*uploadCollector)
+	if !ok {
+		return "", errNotMultipart
+	}
+	u := Upload{
+		Variable: fmt.Sprintf("upload%d", lastUpload.Add(1)),
+		Type:     uploadScalar(ctx),
+		File:     v.Interface(),
+	}
+	collector.mu.Lock()
+	collector.uploads = append(collector.uploads, u)
+	collector.mu.Unlock()
+	return "$" + u.Variable, nil
+}
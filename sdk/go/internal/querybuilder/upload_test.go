@@ -0,0 +1,173 @@
+package querybuilder
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Khan/genqlient/graphql"
+	"github.com/stretchr/testify/require"
+)
+
+type attachment struct {
+	Name string    `json:"name"`
+	File io.Reader `json:"file,omitempty" graphql:"upload=File"`
+}
+
+// label is an enum implementing io.Reader, which isn't a file to upload.
+type label string
+
+func (l label) Read(p []byte) (int, error) {
+	return strings.NewReader(string(l)).Read(p)
+}
+
+type queryClient struct{}
+
+func (queryClient) MakeRequest(ctx context.Context, req *graphql.Request, resp *graphql.Response) error {
+	return json.Unmarshal([]byte(`{"upload": "ok"}`), resp.Data)
+}
+
+func TestMultipart(t *testing.T) {
+	ctx := context.Background()
+	root := Query().
+		Select("upload").Multipart().
+		UploadArg("file", "Upload", strings.NewReader("hello")).
+		Arg("attachments", []attachment{{Name: "a"}, {Name: "b", File: strings.NewReader("world")}}).
+		Arg("label", label("README"))
+
+	q, err := root.build(ctx)
+	require.NoError(t, err)
+	uploads := root.uploads()
+	require.Len(t, uploads, 2)
+	var scalars []string
+	for _, u := range uploads {
+		require.Contains(t, q, "$"+u.Variable+":"+u.Type+"!")
+		scalars = append(scalars, u.Type)
+	}
+	require.ElementsMatch(t, []string{"Upload", "File"}, scalars)
+	require.Contains(t, q, `{name:"a"}`)
+	require.Contains(t, q, `label:README`)
+
+	// Uploads aren't inlined outside multipart selections.
+	_, err = Query().Select("upload").UploadArg("file", "Upload", strings.NewReader("hello")).build(ctx)
+	require.ErrorIs(t, err, errNotMultipart)
+
+	// Only the arguments of the Upload scalar are files.
+	q, err = Query().Select("upload").Arg("label", label("README")).build(ctx)
+	require.NoError(t, err)
+	require.Equal(t, `query{upload(label:README)}`, q)
+}
+
+func TestMultipartExecute(t *testing.T) {
+	type operations struct {
+		Query     string         `json:"query"`
+		Variables map[string]any `json:"variables"`
+	}
+	var (
+		received operations
+		mapping  string
+		contents string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := json.Unmarshal([]byte(r.FormValue("operations")), &received); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		mapping = r.FormValue("map")
+		f, header, err := r.FormFile("0")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer f.Close()
+		b, err := io.ReadAll(f)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		contents = header.Filename + ":" + string(b)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": {"upload": "ok"}}`))
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	var response string
+	root := Query().
+		Select("upload").Multipart().
+		UploadArg("file", "Upload", strings.NewReader("hello")).
+		Bind(&response)
+
+	require.ErrorContains(t, root.Execute(ctx, queryClient{}), "requires a multipart request")
+
+	require.NoError(t, root.Execute(ctx, NewClient(srv.URL, srv.Client())))
+	require.Equal(t, "ok", response)
+
+	variable := root.uploads()[0].Variable
+	require.Contains(t, received.Query, "$"+variable+":Upload!")
+	require.Equal(t, map[string]any{variable: nil}, received.Variables)
+	require.JSONEq(t, `{"0": ["variables.`+variable+`"]}`, mapping)
+	require.Equal(t, "0:hello", contents)
+
+	// The errors of the server are reported.
+	srv.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"errors": [{"message": "upload failed"}]}`))
+	})
+	err := Query().
+		Select("upload").Multipart().
+		UploadArg("file", "Upload", strings.NewReader("hello")).
+		Bind(&response).
+		Execute(ctx, NewClient(srv.URL, srv.Client()))
+	require.ErrorContains(t, err, "upload failed")
+}
+
+func TestMultipartExecuteTwice(t *testing.T) {
+	// The server responds with the contents of the uploaded file.
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f, _, err := r.FormFile("0")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer f.Close()
+		b, err := io.ReadAll(f)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"upload": string(b)}})
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	c := NewClient(srv.URL, srv.Client())
+	var response string
+
+	// Seekable files are sent again from the start.
+	root := Query().
+		Select("upload").Multipart().
+		UploadArg("file", "Upload", strings.NewReader("hello")).
+		Bind(&response)
+	for i := 0; i < 2; i++ {
+		response = ""
+		require.NoError(t, root.Execute(ctx, c))
+		require.Equal(t, "hello", response)
+	}
+
+	// The others can't be sent twice.
+	root = Query().
+		Select("upload").Multipart().
+		UploadArg("file", "Upload", io.MultiReader(strings.NewReader("hello"))).
+		Bind(&response)
+	require.NoError(t, root.Execute(ctx, c))
+	require.Equal(t, "hello", response)
+	require.ErrorIs(t, root.Execute(ctx, c), ErrUploadSent)
+}